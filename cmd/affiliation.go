@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/config"
+	"github.com/eveldcorp/devrel-github/database"
+	"github.com/spf13/cobra"
+)
+
+// The mapping file of logins to organizations.
+var mappingFile string
+
+var affiliationsCmd = &cobra.Command{
+	Use:   "affiliations",
+	Short: "Maps the collected logins to the organizations they are affiliated with",
+	Args:  cobra.NoArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setup(cmd)
+
+		if format != "sql" {
+			logger.Error("affiliations are resolved from the database, use --format sql")
+			os.Exit(1)
+		}
+
+		if mappingFile == "" {
+			mappingFile = cfg.AffiliationsFile
+		}
+
+		if mappingFile == "" {
+			logger.Error("no affiliations file specified, pass --mapping or set affiliations.file")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		mapping, err := config.LoadAffiliations(mappingFile)
+		if err != nil {
+			logger.Error("could not load affiliations", "error", err)
+			os.Exit(1)
+		}
+
+		identities, err := db.GetIdentities(gh.Host())
+		if err != nil {
+			logger.Error("could not query identities", "error", err)
+			os.Exit(1)
+		}
+
+		// The members of the GitHub organizations of every organization.
+		members := map[string]map[string]bool{}
+		for _, o := range mapping.Organizations {
+			for _, org := range o.GitHub {
+				logins, err := gh.QueryOrganizationMembers(org)
+				if err != nil {
+					logger.Error("could not query organization members", "organization", org, "error", err)
+					os.Exit(1)
+				}
+
+				if members[o.Name] == nil {
+					members[o.Name] = map[string]bool{}
+				}
+
+				for _, l := range logins {
+					members[o.Name][strings.ToLower(l)] = true
+				}
+			}
+		}
+
+		affiliations := resolveAffiliations(gh.Host(), *mapping, identities, members)
+
+		err = db.SaveAffiliations(gh.Host(), affiliations)
+		if err != nil {
+			logger.Error("could not add affiliations to database", "error", err)
+			os.Exit(1)
+		}
+
+		logger.Info("Resolved affiliations", "logins", len(affiliations))
+	},
+}
+
+// Resolve the affiliations of every login, by the first source that knows
+// the login: the people of the mapping file, the members of the GitHub
+// organizations, the domain of an email address and the company of a profile.
+// Logins that no source knows are community members.
+func resolveAffiliations(host string, mapping config.Affiliations, identities []database.Identity, members map[string]map[string]bool) []database.Affiliation {
+	// Every login by its lower case form, with its emails and companies.
+	logins := map[string]string{}
+	emails := map[string][]string{}
+	companies := map[string][]string{}
+
+	for _, i := range identities {
+		key := strings.ToLower(i.Login)
+		logins[key] = i.Login
+
+		if i.Email != "" {
+			emails[key] = append(emails[key], i.Email)
+		}
+
+		if i.Company != "" {
+			companies[key] = append(companies[key], i.Company)
+		}
+	}
+
+	people := map[string]config.Person{}
+	for _, p := range mapping.People {
+		key := strings.ToLower(p.Login)
+		people[key] = p
+		if _, ok := logins[key]; !ok {
+			logins[key] = p.Login
+		}
+	}
+
+	for _, m := range members {
+		for key := range m {
+			if _, ok := logins[key]; !ok {
+				logins[key] = key
+			}
+		}
+	}
+
+	keys := []string{}
+	for key := range logins {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	affiliations := []database.Affiliation{}
+	for _, key := range keys {
+		affiliation := database.Affiliation{
+			Host:  host,
+			Login: logins[key],
+		}
+
+		if p, ok := people[key]; ok && len(p.Affiliations) > 0 {
+			for _, pa := range p.Affiliations {
+				o, _ := mapping.Organization(pa.Organization)
+
+				a := affiliation
+				a.Organization = o.Name
+				a.Affiliation = o.Affiliation
+				a.Source = "mapping"
+				a.ValidFrom = optionalDate(pa.FromDate())
+				a.ValidUntil = optionalDate(pa.UntilDate())
+				affiliations = append(affiliations, a)
+			}
+
+			continue
+		}
+
+		o, source, ok := matchOrganization(mapping.Organizations, key, emails[key], companies[key], members)
+		if ok {
+			affiliation.Organization = o.Name
+			affiliation.Affiliation = o.Affiliation
+			affiliation.Source = source
+		} else {
+			affiliation.Affiliation = config.Community
+			affiliation.Source = "default"
+		}
+
+		affiliations = append(affiliations, affiliation)
+	}
+
+	return affiliations
+}
+
+// Match a login to the first organization it is a member of, else the first
+// organization of one of its email domains, else of one of its companies.
+func matchOrganization(organizations []config.Organization, login string, emails []string, companies []string, members map[string]map[string]bool) (config.Organization, string, bool) {
+	for _, o := range organizations {
+		if members[o.Name][login] {
+			return o, "membership", true
+		}
+	}
+
+	for _, o := range organizations {
+		for _, e := range emails {
+			if matchDomain(e, o.Domains) {
+				return o, "email", true
+			}
+		}
+	}
+
+	for _, o := range organizations {
+		for _, c := range companies {
+			if matchCompany(c, o.Companies) {
+				return o, "company", true
+			}
+		}
+	}
+
+	return config.Organization{}, "", false
+}
+
+// Whether the domain of an email address is one of domains or a subdomain of
+// one.
+func matchDomain(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for _, d := range domains {
+		d = strings.ToLower(d)
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Whether a company field, like "@hashicorp" or "HashiCorp, Inc.", contains
+// one of the company names.
+func matchCompany(company string, names []string) bool {
+	company = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(company), "@"))
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(n), "@"))
+		if n != "" && strings.Contains(company, n) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A date that is NULL when it is zero.
+func optionalDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
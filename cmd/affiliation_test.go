@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/config"
+	"github.com/eveldcorp/devrel-github/database"
+)
+
+func TestMatchDomain(t *testing.T) {
+	cases := []struct {
+		email   string
+		domains []string
+		want    bool
+	}{
+		{email: "jane@hashicorp.com", domains: []string{"hashicorp.com"}, want: true},
+		{email: "Jane@HashiCorp.com", domains: []string{"hashicorp.com"}, want: true},
+		{email: "jane@eu.hashicorp.com", domains: []string{"hashicorp.com"}, want: true},
+		{email: "jane@nothashicorp.com", domains: []string{"hashicorp.com"}, want: false},
+		{email: "jane@hashicorp.com.evil.org", domains: []string{"hashicorp.com"}, want: false},
+		{email: "jane@example.com", domains: []string{"hashicorp.com", "example.com"}, want: true},
+		{email: "hashicorp.com", domains: []string{"hashicorp.com"}, want: false},
+		{email: "jane@hashicorp.com", domains: []string{}, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.email, func(t *testing.T) {
+			got := matchDomain(c.email, c.domains)
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMatchCompany(t *testing.T) {
+	cases := []struct {
+		company string
+		names   []string
+		want    bool
+	}{
+		{company: "HashiCorp", names: []string{"hashicorp"}, want: true},
+		{company: "@hashicorp", names: []string{"HashiCorp"}, want: true},
+		{company: "HashiCorp, Inc.", names: []string{"hashicorp"}, want: true},
+		{company: "  @hashicorp ", names: []string{"@hashicorp"}, want: true},
+		{company: "Example", names: []string{"hashicorp"}, want: false},
+		{company: "HashiCorp", names: []string{""}, want: false},
+		{company: "", names: []string{"hashicorp"}, want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.company, func(t *testing.T) {
+			got := matchCompany(c.company, c.names)
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestResolveAffiliations(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2021, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	mapping := config.Affiliations{
+		Organizations: []config.Organization{
+			{Name: "HashiCorp", Affiliation: "employee", GitHub: []string{"hashicorp"}, Domains: []string{"hashicorp.com"}, Companies: []string{"hashicorp"}},
+			{Name: "Example", Affiliation: "partner", Domains: []string{"example.com"}, Companies: []string{"example"}},
+		},
+		People: []config.Person{
+			{Login: "Mapped", Affiliations: []config.PersonAffiliation{
+				{Organization: "example", From: "2020-01-01", Until: "2021-06-30"},
+				{Organization: "hashicorp", From: "2021-07-01"},
+			}},
+		},
+	}
+
+	identities := []database.Identity{
+		{Login: "mapped", Email: "mapped@hashicorp.com"},
+		{Login: "Member", Email: "member@example.com"},
+		{Login: "emailed", Email: "emailed@eu.hashicorp.com", Company: "Example"},
+		{Login: "employed", Company: "@example"},
+		{Login: "stranger", Email: "stranger@gmail.com", Company: "Acme"},
+	}
+
+	members := map[string]map[string]bool{
+		"HashiCorp": {"member": true, "onlymember": true},
+	}
+
+	julyFirst := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	want := []database.Affiliation{
+		{Host: "github.com", Login: "emailed", Organization: "HashiCorp", Affiliation: "employee", Source: "email"},
+		{Host: "github.com", Login: "employed", Organization: "Example", Affiliation: "partner", Source: "company"},
+		{Host: "github.com", Login: "mapped", Organization: "Example", Affiliation: "partner", Source: "mapping", ValidFrom: &from, ValidUntil: &until},
+		{Host: "github.com", Login: "mapped", Organization: "HashiCorp", Affiliation: "employee", Source: "mapping", ValidFrom: &julyFirst},
+		{Host: "github.com", Login: "Member", Organization: "HashiCorp", Affiliation: "employee", Source: "membership"},
+		{Host: "github.com", Login: "onlymember", Organization: "HashiCorp", Affiliation: "employee", Source: "membership"},
+		{Host: "github.com", Login: "stranger", Affiliation: config.Community, Source: "default"},
+	}
+
+	got := resolveAffiliations("github.com", mapping, identities, members)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
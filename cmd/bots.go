@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"path"
+	"strings"
+
+	"github.com/eveldcorp/devrel-github/database"
+)
+
+// Drop the rows of bots before they are written to the output.
+var excludeBots bool
+
+// Whether a login or name matches one of the bot patterns of the config.
+func isBot(login string) bool {
+	if login == "" {
+		return false
+	}
+
+	for _, p := range cfg.BotPatterns {
+		if ok, _ := path.Match(strings.ToLower(p), strings.ToLower(login)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Flag the issues and comments of bots, dropping them with --exclude-bots.
+func flagIssueBots(issues []database.Issue) []database.Issue {
+	flagged := []database.Issue{}
+
+	for _, i := range issues {
+		i.IsBot = i.IsBot || isBot(i.Author)
+		if excludeBots && i.IsBot {
+			continue
+		}
+
+		comments := []database.IssueComment{}
+		for _, c := range i.Comments {
+			c.IsBot = c.IsBot || isBot(c.Author)
+			if excludeBots && c.IsBot {
+				continue
+			}
+			comments = append(comments, c)
+		}
+		i.Comments = comments
+
+		flagged = append(flagged, i)
+	}
+
+	return flagged
+}
+
+// Flag the pullrequests, comments, reviews and review comments of bots,
+// dropping them with --exclude-bots.
+func flagPullrequestBots(pullrequests []database.Pullrequest) []database.Pullrequest {
+	flagged := []database.Pullrequest{}
+
+	for _, p := range pullrequests {
+		p.IsBot = p.IsBot || isBot(p.Author)
+		if excludeBots && p.IsBot {
+			continue
+		}
+
+		comments := []database.PullrequestComment{}
+		for _, c := range p.Comments {
+			c.IsBot = c.IsBot || isBot(c.Author)
+			if excludeBots && c.IsBot {
+				continue
+			}
+			comments = append(comments, c)
+		}
+		p.Comments = comments
+
+		reviews := []database.PullrequestReview{}
+		for _, r := range p.Reviews {
+			r.IsBot = r.IsBot || isBot(r.Author)
+			if excludeBots && r.IsBot {
+				continue
+			}
+			reviews = append(reviews, r)
+		}
+		p.Reviews = reviews
+
+		threads := []database.PullrequestReviewThread{}
+		for _, t := range p.ReviewThreads {
+			comments := []database.PullrequestReviewComment{}
+			for _, c := range t.Comments {
+				c.IsBot = c.IsBot || isBot(c.Author)
+				if excludeBots && c.IsBot {
+					continue
+				}
+				comments = append(comments, c)
+			}
+			t.Comments = comments
+
+			threads = append(threads, t)
+		}
+		p.ReviewThreads = threads
+
+		flagged = append(flagged, p)
+	}
+
+	return flagged
+}
+
+// Flag the discussions, comments and replies of bots, dropping them with
+// --exclude-bots.
+func flagDiscussionBots(discussions []database.Discussion) []database.Discussion {
+	flagged := []database.Discussion{}
+
+	for _, d := range discussions {
+		d.IsBot = d.IsBot || isBot(d.Author)
+		if excludeBots && d.IsBot {
+			continue
+		}
+
+		d.Comments = flagDiscussionCommentBots(d.Comments)
+		flagged = append(flagged, d)
+	}
+
+	return flagged
+}
+
+// Flag the discussion comments and replies of bots, dropping them with
+// --exclude-bots.
+func flagDiscussionCommentBots(comments []database.DiscussionComment) []database.DiscussionComment {
+	flagged := []database.DiscussionComment{}
+
+	for _, c := range comments {
+		c.IsBot = c.IsBot || isBot(c.Author)
+		if excludeBots && c.IsBot {
+			continue
+		}
+
+		c.Replies = flagDiscussionCommentBots(c.Replies)
+		flagged = append(flagged, c)
+	}
+
+	return flagged
+}
+
+// Flag the commits of bots by the login or name of their author, dropping
+// them with --exclude-bots.
+func flagCommitBots(commits []database.Commit) []database.Commit {
+	flagged := []database.Commit{}
+
+	for _, c := range commits {
+		c.IsBot = isBot(c.Author) || isBot(c.AuthorName)
+		if excludeBots && c.IsBot {
+			continue
+		}
+
+		flagged = append(flagged, c)
+	}
+
+	return flagged
+}
+
+// Drop the profiles of bots with --exclude-bots.
+func excludeUserBots(users []database.User) []database.User {
+	if !excludeBots {
+		return users
+	}
+
+	flagged := []database.User{}
+	for _, u := range users {
+		if u.Type == "Bot" || isBot(u.Login) {
+			continue
+		}
+
+		flagged = append(flagged, u)
+	}
+
+	return flagged
+}
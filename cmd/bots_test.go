@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/eveldcorp/devrel-github/config"
+)
+
+func TestIsBot(t *testing.T) {
+	cases := []struct {
+		name     string
+		patterns []string
+		login    string
+		want     bool
+	}{
+		{name: "empty login", patterns: []string{"*"}, login: "", want: false},
+		{name: "default pattern", patterns: []string{`*\[bot\]`}, login: "dependabot[bot]", want: true},
+		{name: "default pattern user", patterns: []string{`*\[bot\]`}, login: "octocat", want: false},
+		{name: "default pattern case", patterns: []string{`*\[bot\]`}, login: "Renovate[BOT]", want: true},
+		{name: "default pattern user ending in t", patterns: []string{`*\[bot\]`}, login: "matt", want: false},
+		{name: "escaped with glob", patterns: []string{`dependabot*\[bot\]`}, login: "dependabot-preview[bot]", want: true},
+		{name: "escaped with other name", patterns: []string{`dependabot*\[bot\]`}, login: "renovate[bot]", want: false},
+		{name: "unescaped is a character class", patterns: []string{"*[bot]"}, login: "matt", want: true},
+		{name: "unescaped does not match the suffix", patterns: []string{"*[bot]"}, login: "dependabot[bot]", want: false},
+		{name: "no patterns", patterns: []string{}, login: "github-actions[bot]", want: false},
+		{name: "prefix pattern", patterns: []string{"renovate*"}, login: "renovate-approve", want: true},
+		{name: "pattern case", patterns: []string{"HashiCorp-CLA"}, login: "hashicorp-cla", want: true},
+		{name: "no match", patterns: []string{"renovate*", "*-cla-bot"}, login: "octocat", want: false},
+		{name: "invalid pattern", patterns: []string{"[", "*-cla-bot"}, login: "acme-cla-bot", want: true},
+	}
+
+	defer func(c *config.Config) { cfg = c }(cfg)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg = &config.Config{BotPatterns: c.patterns}
+
+			got := isBot(c.login)
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
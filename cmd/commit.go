@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eveldcorp/devrel-github/database"
+	"github.com/spf13/cobra"
+)
+
+// The branches to query besides the default branch.
+var branches []string
+
+var commitsCmd = &cobra.Command{
+	Use:   "commits [owner/repository...]",
+	Short: "Queries the commits of one or more repositories at owner/repository",
+	Args:  cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		openOutput()
+		ok := forEachTarget(targets, queryCommits)
+		closeOutput()
+
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// Query the commits of the default branch and the configured branches of a
+// single repository, writing every page of commits to the output as soon as it
+// has been queried.
+func queryCommits(t target) error {
+	var metadata database.Metadata
+	var err error
+	heads := database.Heads{}
+
+	// The branches flag overrides the config file.
+	query := branches
+	if len(query) == 0 {
+		query = cfg.CommitBranches
+	}
+
+	if format == "sql" {
+		metadata, err = db.GetMetadata(gh.Host(), t.owner, t.repository)
+		if err != nil {
+			return fmt.Errorf("could not query metadata: %v", err)
+		}
+
+		if sinceFlag == "" {
+			// Continue from the heads of the last run.
+			heads = metadata.CommitsHeads
+		}
+	}
+
+	// Query the commits.
+	err = gh.QueryCommits(t.owner, t.repository, query, t.since, heads, t.limit, func(branch string, commits []database.Commit, head string, orphaned []string) error {
+		commits = flagCommitBots(commits)
+
+		if format != "sql" {
+			// Output the commits as JSON.
+			for _, c := range commits {
+				err := jsonOutput.Write(c)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		// Write the page of commits to the database.
+		metadata, err = db.SaveCommits(commits, metadata, branch, head, orphaned)
+		if err != nil {
+			return fmt.Errorf("could not add commits to database: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not query commits: %v", err)
+	}
+
+	return nil
+}
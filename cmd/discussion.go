@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/eveldcorp/devrel-github/database"
+	"github.com/spf13/cobra"
+)
+
+var discussionsCmd = &cobra.Command{
+	Use:   "discussions [owner/repository...]",
+	Short: "Queries the discussions of one or more repositories at owner/repository",
+	Args:  cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		openOutput()
+		ok := forEachTarget(targets, queryDiscussions)
+		closeOutput()
+
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// Query the discussions of a single repository, writing every page of
+// discussions to the output as soon as it has been queried.
+func queryDiscussions(t target) error {
+	var metadata database.Metadata
+	var checkpoint *database.Checkpoint
+	var err error
+	from := t.since
+
+	if format == "sql" {
+		metadata, err = db.GetMetadata(gh.Host(), t.owner, t.repository)
+		if err != nil {
+			return fmt.Errorf("could not query metadata: %v", err)
+		}
+
+		if sinceFlag == "" && metadata.DiscussionsUpdatedAt.After(from) {
+			// Get since from the database and continue from there.
+			from = metadata.DiscussionsUpdatedAt
+		}
+
+		c, err := db.GetCheckpoint(gh.Host(), t.owner, t.repository, "discussions")
+		if err != nil {
+			return fmt.Errorf("could not query checkpoint: %v", err)
+		}
+
+		if (c.Cursor != "" || c.Number != 0) && (sinceFlag == "" || c.Since.Equal(from)) {
+			// Resume the interrupted query with the since it was started with.
+			from = c.Since
+			checkpoint = &c
+		} else {
+			checkpoint = &database.Checkpoint{}
+		}
+	}
+
+	// The discussions written, without the partial ones of additional comments.
+	count := 0
+
+	// Query the discussions.
+	err = gh.QueryDiscussions(t.owner, t.repository, from, t.limit, checkpoint, func(discussions []database.Discussion, c database.Checkpoint) error {
+		if c.CommentsCursor == "" {
+			count += len(discussions)
+		}
+
+		discussions = flagDiscussionBots(discussions)
+
+		if format != "sql" {
+			// Output the discussions as JSON.
+			for _, d := range discussions {
+				err := jsonOutput.Write(d)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		// Write the page of discussions to the database.
+		metadata, err = db.SaveDiscussions(discussions, metadata, c)
+		if err != nil {
+			return fmt.Errorf("could not add discussions to database: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not query discussions: %v", err)
+	}
+
+	// The discussions are queried newest first, so a limited run keeps its
+	// checkpoint for the next run to continue with the older discussions.
+	if format == "sql" && (t.limit == 0 || count < t.limit) {
+		// The query completed, so there is nothing left to resume.
+		err = db.DeleteCheckpoint(gh.Host(), t.owner, t.repository, "discussions")
+		if err != nil {
+			return fmt.Errorf("could not delete checkpoint: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -1,77 +1,96 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/eveldcorp/devrel-github/database"
 	"github.com/spf13/cobra"
 )
 
 var issuesCmd = &cobra.Command{
-	Use:   "issues [owner] [repository]",
-	Short: "Queries the issues of a repository at owner/repository",
-	Args:  cobra.ExactArgs(2),
+	Use:   "issues [owner/repository...]",
+	Short: "Queries the issues of one or more repositories at owner/repository",
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		if sinceFlag == "" {
+		openOutput()
+		ok := forEachTarget(targets, queryIssues)
+		closeOutput()
+
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// Query the issues of a single repository, writing every page of issues to
+// the output as soon as it has been queried.
+func queryIssues(t target) error {
+	var metadata database.Metadata
+	var checkpoint *database.Checkpoint
+	var err error
+	from := t.since
+
+	if format == "sql" {
+		metadata, err = db.GetMetadata(gh.Host(), t.owner, t.repository)
+		if err != nil {
+			return fmt.Errorf("could not query metadata: %v", err)
+		}
+
+		if sinceFlag == "" && metadata.IssuesUpdatedAt.After(from) {
 			// Get since from the database and continue from there.
-			since = metadata.IssuesUpdatedAt
+			from = metadata.IssuesUpdatedAt
 		}
 
-		// Query the issues.
-		issues, err := gh.QueryIssues(owner, repository, since, limit)
+		c, err := db.GetCheckpoint(gh.Host(), t.owner, t.repository, "issues")
 		if err != nil {
-			logger.Error("could not query issues", "error", err)
-			os.Exit(1)
+			return fmt.Errorf("could not query checkpoint: %v", err)
 		}
 
-		if format == "sql" {
-			// Write the issues to the database.
+		if c.Cursor != "" && (sinceFlag == "" || c.Since.Equal(from)) {
+			// Resume the interrupted query with the since it was started with.
+			from = c.Since
+			checkpoint = &c
+		} else {
+			checkpoint = &database.Checkpoint{}
+		}
+	}
+
+	// Query the issues.
+	err = gh.QueryIssues(t.owner, t.repository, from, t.limit, checkpoint, func(issues []database.Issue, c database.Checkpoint) error {
+		issues = flagIssueBots(issues)
+
+		if format != "sql" {
+			// Output the issues as JSON.
 			for _, i := range issues {
-				_, err := db.AddIssue(i)
+				err := jsonOutput.Write(i)
 				if err != nil {
-					logger.Error("could not add issue to database", "error", err)
-					os.Exit(1)
+					return err
 				}
+			}
 
-				for _, r := range i.Reactions {
-					r.Issue = i.ID
-					_, err := db.AddIssueReaction(r)
-					if err != nil {
-						logger.Error("could not add issue reaction to database", "error", err)
-						os.Exit(1)
-					}
-				}
+			return nil
+		}
 
-				for _, c := range i.Comments {
-					c.Issue = i.ID
-					_, err := db.AddIssueComment(c)
-					if err != nil {
-						logger.Error("could not add issue comment to database", "error", err)
-						os.Exit(1)
-					}
-
-					for _, cr := range c.Reactions {
-						cr.Issue = i.ID
-						_, err := db.AddIssueCommentReaction(cr)
-						if err != nil {
-							logger.Error("could not add issue comment reaction to database", "error", err)
-							os.Exit(1)
-						}
-					}
-				}
+		// Write the page of issues to the database.
+		metadata, err = db.SaveIssues(issues, metadata, c)
+		if err != nil {
+			return fmt.Errorf("could not add issues to database: %v", err)
+		}
 
-				// Update the metadata.
-				if i.UpdatedAt.After(metadata.IssuesUpdatedAt) {
-					metadata.IssuesUpdatedAt = i.UpdatedAt
-					metadata, err = db.AddMetadata(metadata)
-					if err != nil {
-						logger.Error("could not update metadata", "error", err)
-						os.Exit(1)
-					}
-				}
-			}
-		} else {
-			// Output the issues as JSON.
-			outputJSON(issues, output)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not query issues: %v", err)
+	}
+
+	if format == "sql" {
+		// The query completed, so there is nothing left to resume.
+		err = db.DeleteCheckpoint(gh.Host(), t.owner, t.repository, "issues")
+		if err != nil {
+			return fmt.Errorf("could not delete checkpoint: %v", err)
 		}
-	},
+	}
+
+	return nil
 }
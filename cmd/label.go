@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var labelsCmd = &cobra.Command{
+	Use:   "labels [owner/name...]",
+	Short: "Queries the labels defined in one or more repositories at owner/name",
+	Args:  cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		openOutput()
+		ok := forEachTarget(targets, queryLabels)
+		closeOutput()
+
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// Query the labels of a single repository and write them to the output.
+func queryLabels(t target) error {
+	// Query the labels.
+	labels, err := gh.QueryLabelDefinitions(t.owner, t.repository)
+	if err != nil {
+		return fmt.Errorf("could not query labels: %v", err)
+	}
+
+	if format != "sql" {
+		// Output the labels as JSON.
+		for _, l := range labels {
+			err := jsonOutput.Write(l)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// Write the labels to the database.
+	err = db.SaveLabels(gh.Host(), t.owner, t.repository, labels)
+	if err != nil {
+		return fmt.Errorf("could not add labels to database: %v", err)
+	}
+
+	return nil
+}
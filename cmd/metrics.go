@@ -1,40 +1,78 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
 var metricsCmd = &cobra.Command{
-	Use:   "metrics [owner] [name]",
-	Short: "Queries the metrics of a repository at owner/name",
-	Args:  cobra.ExactArgs(2),
+	Use:   "metrics [owner/name...]",
+	Short: "Queries the metrics of one or more repositories at owner/name",
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Query the metrics.
-		metrics, err := gh.QueryMetrics(owner, repository)
-		if err != nil {
-			logger.Error("could not query metrics", "error", err)
+		openOutput()
+		ok := forEachTarget(targets, queryMetrics)
+		closeOutput()
+
+		if !ok {
 			os.Exit(1)
 		}
+	},
+}
+
+// Query the metrics of a single repository and write them to the output.
+func queryMetrics(t target) error {
+	// Query the metrics.
+	metrics, err := gh.QueryMetrics(t.owner, t.repository, cfg.TrafficPeriods)
+	if err != nil {
+		return fmt.Errorf("could not query metrics: %v", err)
+	}
 
-		if format == "sql" {
-			// Write the metrics to the database.
-			db.AddMetrics(metrics)
+	if format != "sql" {
+		// Output the metrics as JSON.
+		return jsonOutput.Write(metrics)
+	}
 
-			db.AddTrafficClones(metrics.Clones)
-			db.AddTrafficViews(metrics.Views)
+	// Write the metrics to the database.
+	_, err = db.AddMetrics(metrics)
+	if err != nil {
+		return fmt.Errorf("could not add metrics to database: %v", err)
+	}
 
-			for _, r := range metrics.Referrers {
-				db.AddTrafficReferrer(r)
-			}
+	err = db.SaveMetricsHistory(metrics)
+	if err != nil {
+		return fmt.Errorf("could not add metrics history to database: %v", err)
+	}
 
-			for _, p := range metrics.Paths {
-				db.AddTrafficPath(p)
-			}
-		} else {
-			// Output the releases as JSON.
-			outputJSON(metrics, output)
+	for _, c := range metrics.Clones {
+		_, err := db.AddTrafficClones(c)
+		if err != nil {
+			return fmt.Errorf("could not add traffic clones to database: %v", err)
 		}
-	},
+	}
+
+	for _, v := range metrics.Views {
+		_, err := db.AddTrafficViews(v)
+		if err != nil {
+			return fmt.Errorf("could not add traffic views to database: %v", err)
+		}
+	}
+
+	for _, r := range metrics.Referrers {
+		_, err := db.AddTrafficReferrer(r)
+		if err != nil {
+			return fmt.Errorf("could not add traffic referrer to database: %v", err)
+		}
+	}
+
+	for _, p := range metrics.Paths {
+		_, err := db.AddTrafficPath(p)
+		if err != nil {
+			return fmt.Errorf("could not add traffic path to database: %v", err)
+		}
+	}
+
+	return nil
 }
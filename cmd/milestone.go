@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var milestonesCmd = &cobra.Command{
+	Use:   "milestones [owner/name...]",
+	Short: "Queries the milestones of one or more repositories at owner/name",
+	Args:  cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		openOutput()
+		ok := forEachTarget(targets, queryMilestones)
+		closeOutput()
+
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// Query the milestones of a single repository and write them to the output.
+func queryMilestones(t target) error {
+	// Query the milestones.
+	milestones, err := gh.QueryMilestones(t.owner, t.repository)
+	if err != nil {
+		return fmt.Errorf("could not query milestones: %v", err)
+	}
+
+	if format != "sql" {
+		// Output the milestones as JSON.
+		for _, m := range milestones {
+			err := jsonOutput.Write(m)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// Write the milestones to the database.
+	err = db.SaveMilestones(gh.Host(), t.owner, t.repository, milestones)
+	if err != nil {
+		return fmt.Errorf("could not add milestones to database: %v", err)
+	}
+
+	return nil
+}
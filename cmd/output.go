@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Writes the data as a JSON array, one element at a time, so that results
+// can be written as soon as they have been queried.
+type jsonWriter struct {
+	w     io.Writer
+	file  *os.File
+	count int
+}
+
+// Output the data as JSON to stdout, or to the file at dest.
+func newJSONWriter(dest string) (*jsonWriter, error) {
+	if dest == "" {
+		return &jsonWriter{w: os.Stdout}, nil
+	}
+
+	file, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("could not open json file: %v+", err)
+	}
+
+	return &jsonWriter{w: file, file: file}, nil
+}
+
+// Write a single element of the array.
+func (j *jsonWriter) Write(input interface{}) error {
+	data, err := json.MarshalIndent(&input, " ", " ")
+	if err != nil {
+		return fmt.Errorf("could not marshall json: %v+", err)
+	}
+
+	separator := ",\n "
+	if j.count == 0 {
+		separator = "[\n "
+	}
+
+	_, err = fmt.Fprint(j.w, separator, string(data))
+	if err != nil {
+		return fmt.Errorf("could not write json: %v+", err)
+	}
+
+	j.count++
+
+	return nil
+}
+
+// Close the array and the file.
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+
+	_, err := fmt.Fprint(j.w, end)
+	if err != nil {
+		return fmt.Errorf("could not write json: %v+", err)
+	}
+
+	if j.file != nil {
+		return j.file.Close()
+	}
+
+	return nil
+}
+
+var jsonOutput *jsonWriter
+
+// Open the JSON output when the output format is json.
+func openOutput() {
+	if format != "json" {
+		return
+	}
+
+	jsonOutput, err = newJSONWriter(output)
+	if err != nil {
+		logger.Error("could not open output", "error", err)
+		os.Exit(1)
+	}
+}
+
+// Close the JSON output when the output format is json.
+func closeOutput() {
+	if jsonOutput == nil {
+		return
+	}
+
+	err := jsonOutput.Close()
+	if err != nil {
+		logger.Error("could not close output", "error", err)
+		os.Exit(1)
+	}
+}
@@ -1,94 +1,105 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/eveldcorp/devrel-github/database"
 	"github.com/spf13/cobra"
 )
 
 var pullrequestsCmd = &cobra.Command{
-	Use:   "pullrequests [owner] [name]",
-	Short: "Queries the pullrequests of a repository at owner/name",
-	Args:  cobra.ExactArgs(2),
+	Use:   "pullrequests [owner/name...]",
+	Short: "Queries the pullrequests of one or more repositories at owner/name",
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		if sinceFlag == "" {
+		openOutput()
+		ok := forEachTarget(targets, queryPullrequests)
+		closeOutput()
+
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// Query the pullrequests of a single repository, writing every page of
+// pullrequests to the output as soon as it has been queried.
+func queryPullrequests(t target) error {
+	var metadata database.Metadata
+	var checkpoint *database.Checkpoint
+	var err error
+	from := t.since
+
+	if format == "sql" {
+		metadata, err = db.GetMetadata(gh.Host(), t.owner, t.repository)
+		if err != nil {
+			return fmt.Errorf("could not query metadata: %v", err)
+		}
+
+		if sinceFlag == "" && metadata.PullrequestsUpdatedAt.After(from) {
 			// Get since from the database and continue from there.
-			since = metadata.PullrequestsUpdatedAt
+			from = metadata.PullrequestsUpdatedAt
 		}
 
-		// Query the pullrequests.
-		pullrequests, err := gh.QueryPullrequests(owner, repository, since, limit)
+		c, err := db.GetCheckpoint(gh.Host(), t.owner, t.repository, "pullrequests")
 		if err != nil {
-			logger.Error("could not query pullrequests", "error", err)
-			os.Exit(1)
+			return fmt.Errorf("could not query checkpoint: %v", err)
+		}
+
+		if (c.Cursor != "" || c.Number != 0) && (sinceFlag == "" || c.Since.Equal(from)) {
+			// Resume the interrupted query with the since it was started with.
+			from = c.Since
+			checkpoint = &c
+		} else {
+			checkpoint = &database.Checkpoint{}
+		}
+	}
+
+	// The pullrequests written, without the partial ones of additional comments.
+	count := 0
+
+	// Query the pullrequests.
+	err = gh.QueryPullrequests(t.owner, t.repository, from, t.limit, checkpoint, func(pullrequests []database.Pullrequest, c database.Checkpoint) error {
+		if c.CommentsCursor == "" {
+			count += len(pullrequests)
 		}
 
-		if format == "sql" {
-			// Write the pullrequests to the database.
+		pullrequests = flagPullrequestBots(pullrequests)
+
+		if format != "sql" {
+			// Output the pullrequests as JSON.
 			for _, p := range pullrequests {
-				_, err := db.AddPullrequest(p)
+				err := jsonOutput.Write(p)
 				if err != nil {
-					logger.Error("could not add pullrequest to database", "error", err)
-					os.Exit(1)
-				}
-
-				for _, r := range p.Reactions {
-					r.Pullrequest = p.ID
-					_, err := db.AddPullrequestReaction(r)
-					if err != nil {
-						logger.Error("could not add pullrequest reaction to database", "error", err)
-						os.Exit(1)
-					}
+					return err
 				}
+			}
 
-				for _, c := range p.Comments {
-					c.Pullrequest = p.ID
-					_, err := db.AddPullrequestComment(c)
-					if err != nil {
-						logger.Error("could not add pullrequest comment to database", "error", err)
-						os.Exit(1)
-					}
-
-					for _, cr := range c.Reactions {
-						cr.Pullrequest = p.ID
-						_, err := db.AddPullrequestCommentReaction(cr)
-						if err != nil {
-							logger.Error("could not add pullrequest comment reaction to database", "error", err)
-							os.Exit(1)
-						}
-					}
-				}
+			return nil
+		}
 
-				for _, r := range p.Reviews {
-					r.Pullrequest = p.ID
-					_, err := db.AddPullrequestReview(r)
-					if err != nil {
-						logger.Error("could not add pullrequest review to database", "error", err)
-						os.Exit(1)
-					}
-				}
+		// Write the page of pullrequests to the database.
+		metadata, err = db.SavePullrequests(pullrequests, metadata, c)
+		if err != nil {
+			return fmt.Errorf("could not add pullrequests to database: %v", err)
+		}
 
-				for _, f := range p.Files {
-					f.Pullrequest = p.ID
-					_, err := db.AddPullrequestFile(f)
-					if err != nil {
-						logger.Error("could not add pullrequest file to database", "error", err)
-						os.Exit(1)
-					}
-				}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not query pullrequests: %v", err)
+	}
 
-				// Update the metadata.
-				if p.UpdatedAt.After(metadata.PullrequestsUpdatedAt) {
-					metadata.PullrequestsUpdatedAt = p.UpdatedAt
-					metadata, err = db.AddMetadata(metadata)
-					if err != nil {
-						logger.Error("could not update metadata", "error", err)
-						os.Exit(1)
-					}
-				}
-			}
-		} else {
-			outputJSON(pullrequests, output)
+	// The pullrequests are queried newest first, so a limited run keeps its
+	// checkpoint for the next run to continue with the older pullrequests.
+	if format == "sql" && (t.limit == 0 || count < t.limit) {
+		// The query completed, so there is nothing left to resume.
+		err = db.DeleteCheckpoint(gh.Host(), t.owner, t.repository, "pullrequests")
+		if err != nil {
+			return fmt.Errorf("could not delete checkpoint: %v", err)
 		}
-	},
+	}
+
+	return nil
 }
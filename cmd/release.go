@@ -1,43 +1,144 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"time"
 
+	"github.com/eveldcorp/devrel-github/database"
 	"github.com/spf13/cobra"
 )
 
+// Only refresh the download counts of the stored release assets.
+var refreshDownloads bool
+
 var releasesCmd = &cobra.Command{
-	Use:   "releases [owner] [name]",
-	Short: "Queries the releases of a repository at owner/name",
-	Args:  cobra.ExactArgs(2),
+	Use:   "releases [owner/name...]",
+	Short: "Queries the releases of one or more repositories at owner/name",
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Query the releases.
-		releases, err := gh.QueryReleases(owner, repository, limit)
-		if err != nil {
-			logger.Error("could not query releases", "error", err)
+		collect := queryReleases
+		if refreshDownloads {
+			collect = queryReleaseDownloads
+		}
+
+		openOutput()
+		ok := forEachTarget(targets, collect)
+		closeOutput()
+
+		if !ok {
 			os.Exit(1)
 		}
+	},
+}
+
+// Query the releases of a single repository created since the newest release
+// of the last run, writing every page of releases to the output as soon as it
+// has been queried.
+func queryReleases(t target) error {
+	var metadata database.Metadata
+	var err error
+	from := t.since
+
+	if format == "sql" {
+		metadata, err = db.GetMetadata(gh.Host(), t.owner, t.repository)
+		if err != nil {
+			return fmt.Errorf("could not query metadata: %v", err)
+		}
 
-		if format == "sql" {
-			// Write the releases to the database.
+		if sinceFlag == "" && metadata.ReleasesUpdatedAt.After(from) {
+			// Get since from the database and continue from there.
+			from = metadata.ReleasesUpdatedAt
+		}
+	}
+
+	count := 0
+	newest := time.Time{}
+
+	// Query the releases.
+	err = gh.QueryReleases(t.owner, t.repository, from, t.limit, func(releases []database.Release) error {
+		count += len(releases)
+
+		if format != "sql" {
+			// Output the releases as JSON.
 			for _, r := range releases {
-				_, err := db.AddRelease(r)
+				err := jsonOutput.Write(r)
 				if err != nil {
-					logger.Error("could not add release to database", "error", err)
-					os.Exit(1)
+					return err
 				}
+			}
+
+			return nil
+		}
+
+		for _, r := range releases {
+			if r.CreatedAt.After(newest) {
+				newest = r.CreatedAt
+			}
+		}
 
-				for _, a := range r.Assets {
-					_, err := db.AddReleaseAsset(a)
-					if err != nil {
-						logger.Error("could not add release asset to database", "error", err)
-						os.Exit(1)
-					}
+		// Write the page of releases to the database.
+		err := db.SaveReleases(releases)
+		if err != nil {
+			return fmt.Errorf("could not add releases to database: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not query releases: %v", err)
+	}
+
+	// The releases are queried newest first, so the metadata is only advanced
+	// once every release since the last run has been written. A limited run
+	// may have stopped before that.
+	if format == "sql" && (t.limit == 0 || count < t.limit) {
+		_, err = db.SaveReleasesUpdatedAt(metadata, newest)
+		if err != nil {
+			return fmt.Errorf("could not update metadata: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Query the new releases of a single repository, and refresh the download
+// counts of the releases collected by previous runs.
+func queryReleasesAndDownloads(t target) error {
+	err := queryReleases(t)
+	if err != nil {
+		return err
+	}
+
+	return queryReleaseDownloads(t)
+}
+
+// Refresh the download counts of the release assets of a single repository.
+func queryReleaseDownloads(t target) error {
+	err := gh.QueryReleaseDownloads(t.owner, t.repository, func(assets []database.ReleaseAsset) error {
+		if format != "sql" {
+			// Output the assets as JSON.
+			for _, a := range assets {
+				err := jsonOutput.Write(a)
+				if err != nil {
+					return err
 				}
 			}
-		} else {
-			// Output the releases as JSON.
-			outputJSON(releases, output)
+
+			return nil
 		}
-	},
+
+		// Update the stored assets.
+		err := db.UpdateReleaseDownloads(assets)
+		if err != nil {
+			return fmt.Errorf("could not update release downloads in database: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not query release downloads: %v", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/config"
+	"github.com/eveldcorp/devrel-github/database"
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+)
+
+// The collector of each kind of data.
+var collectors = map[string]func(t target) error{
+	"issues":       queryIssues,
+	"pullrequests": queryPullrequests,
+	"discussions":  queryDiscussions,
+	"commits":      queryCommits,
+	"releases":     queryReleasesAndDownloads,
+	"labels":       queryLabels,
+	"milestones":   queryMilestones,
+	"metrics":      queryMetrics,
+	"users":        queryUsers,
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [owner/repository...]",
+	Short: "Runs the collectors of every kind of data on a schedule",
+	Args:  cobra.ArbitraryArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Stop the queries of running collectors on shutdown.
+		ctx, stopSignals = signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+		setup(cmd)
+
+		if format != "sql" {
+			logger.Error("serve can only write to a database, use --format sql")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		defer stopSignals()
+
+		s := &scheduler{
+			ctx:    ctx,
+			cmd:    cmd,
+			args:   args,
+			random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		}
+
+		c := cron.New(cron.WithLogger(cron.PrintfLogger(logger.Named("Scheduler").StandardLogger(nil))))
+
+		for _, kind := range config.Kinds {
+			kind := kind
+
+			schedule, err := cron.ParseStandard(cfg.Schedules[kind])
+			if err != nil {
+				logger.Error("invalid schedule", "kind", kind, "schedule", cfg.Schedules[kind], "error", err)
+				os.Exit(1)
+			}
+
+			// Skip a run when the previous run of the same kind is still going.
+			job := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(func() {
+				s.run(kind, cfg.Jitter)
+			}))
+			c.Schedule(schedule, job)
+
+			// Catch up when the last run was missed.
+			last, err := db.GetSchedule(kind)
+			if err != nil {
+				logger.Error("could not query schedule", "kind", kind, "error", err)
+				os.Exit(1)
+			}
+
+			if last.LastRunAt.IsZero() || schedule.Next(last.LastRunAt).Before(time.Now()) {
+				logger.Info("Catching up on missed run", "kind", kind, "last_run_at", last.LastRunAt)
+				// Unlike the runs of cron, which Stop waits for, the catch up
+				// run has to be waited for on shutdown.
+				s.wg.Add(1)
+				go func() {
+					defer s.wg.Done()
+					job.Run()
+				}()
+			}
+
+			logger.Info("Scheduled collector", "kind", kind, "schedule", cfg.Schedules[kind])
+		}
+
+		c.Start()
+
+		<-ctx.Done()
+		logger.Info("Shutting down, waiting for running collectors to finish")
+
+		<-c.Stop().Done()
+		s.wg.Wait()
+	},
+}
+
+// Stops relaying the shutdown signals to the context.
+var stopSignals context.CancelFunc
+
+// Runs the collectors, one at a time so that collectors of different kinds do
+// not race on the metadata of a repository.
+type scheduler struct {
+	ctx  context.Context
+	cmd  *cobra.Command
+	args []string
+	lock sync.Mutex
+	wg   sync.WaitGroup
+
+	// The source of the jitter, which is not safe for concurrent use.
+	random     *rand.Rand
+	randomLock sync.Mutex
+}
+
+// Run the collector of a kind of data for every repository after a random
+// delay of up to jitter.
+func (s *scheduler) run(kind string, jitter time.Duration) {
+	if jitter > 0 {
+		select {
+		case <-time.After(s.delay(jitter)):
+		case <-s.ctx.Done():
+			return
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	start := time.Now().UTC()
+	logger.Info("Starting run", "kind", kind)
+
+	targets, err := resolveTargets(s.cmd, s.args, kind)
+	if err != nil {
+		logger.Error("could not resolve repositories", "kind", kind, "error", err)
+		return
+	}
+
+	for _, t := range targets {
+		if s.ctx.Err() != nil {
+			logger.Info("Stopping run", "kind", kind)
+			return
+		}
+
+		logger.Info("Querying repository", "kind", kind, "owner", t.owner, "repository", t.repository)
+
+		err := collectors[kind](t)
+		if err != nil && s.ctx.Err() != nil {
+			logger.Info("Stopping run", "kind", kind)
+			return
+		}
+		if err != nil {
+			logger.Error("could not query repository", "kind", kind, "owner", t.owner, "repository", t.repository, "error", err)
+		}
+	}
+
+	_, err = db.AddSchedule(database.Schedule{Kind: kind, LastRunAt: start})
+	if err != nil {
+		logger.Error("could not update schedule", "kind", kind, "error", err)
+	}
+
+	logger.Info("Finished run", "kind", kind, "duration", time.Since(start))
+}
+
+// A random delay of up to jitter.
+func (s *scheduler) delay(jitter time.Duration) time.Duration {
+	s.randomLock.Lock()
+	defer s.randomLock.Unlock()
+
+	return time.Duration(s.random.Int63n(int64(jitter)))
+}
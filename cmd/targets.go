@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/config"
+	"github.com/eveldcorp/devrel-github/database"
+)
+
+// A repository to query. Data updated before since is never queried, and at
+// most limit results are returned when it is non-zero.
+type target struct {
+	owner      string
+	repository string
+	since      time.Time
+	limit      int
+}
+
+// Parse the CLI args into targets. Args are either owner/repository pairs, or
+// the legacy form of a separate owner and repository.
+func parseTargets(args []string) ([]target, error) {
+	targets := []target{}
+
+	if len(args) == 2 && !strings.Contains(args[0], "/") && !strings.Contains(args[1], "/") {
+		return append(targets, target{owner: args[0], repository: args[1]}), nil
+	}
+
+	for _, a := range args {
+		parts := strings.Split(a, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("%q is not of the form owner/repository", a)
+		}
+
+		targets = appendTargets(targets, target{owner: parts[0], repository: parts[1]})
+	}
+
+	return targets, nil
+}
+
+// Append targets that are not already in the list.
+func appendTargets(targets []target, add ...target) []target {
+	for _, a := range add {
+		found := false
+		for _, t := range targets {
+			if strings.EqualFold(t.owner, a.owner) && strings.EqualFold(t.repository, a.repository) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			targets = append(targets, a)
+		}
+	}
+
+	return targets
+}
+
+// Query the repositories of an organization and filter them.
+func queryOrganization(organization string, include []string, exclude []string, skipArchived bool, skipForks bool) ([]target, error) {
+	repositories, err := gh.QueryRepositories(organization)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterRepositories(repositories, include, exclude, skipArchived, skipForks)
+}
+
+// Build the targets of the config file that collect the kind of data.
+func configTargets(repositories []config.Repository, kind string) ([]target, error) {
+	targets := []target{}
+
+	for _, r := range repositories {
+		if !r.Collects(kind) {
+			continue
+		}
+
+		add := []target{}
+		if r.Repository != "" {
+			parts := strings.Split(r.Repository, "/")
+			add = append(add, target{owner: parts[0], repository: parts[1]})
+		} else {
+			skipArchived := r.SkipArchived == nil || *r.SkipArchived
+			skipForks := r.SkipForks == nil || *r.SkipForks
+
+			orgTargets, err := queryOrganization(r.Organization, r.Include, r.Exclude, skipArchived, skipForks)
+			if err != nil {
+				return nil, fmt.Errorf("could not query organization %s: %v", r.Organization, err)
+			}
+			add = append(add, orgTargets...)
+		}
+
+		for i := range add {
+			add[i].since = r.SinceTime()
+			add[i].limit = r.Limit
+		}
+
+		targets = appendTargets(targets, add...)
+	}
+
+	return targets, nil
+}
+
+// Filter the repositories of an organization using glob patterns on the
+// repository name.
+func filterRepositories(repositories []database.Repository, include []string, exclude []string, skipArchived bool, skipForks bool) ([]target, error) {
+	targets := []target{}
+
+	for _, r := range repositories {
+		if skipArchived && r.IsArchived {
+			continue
+		}
+
+		if skipForks && r.IsFork {
+			continue
+		}
+
+		included := len(include) == 0
+		for _, pattern := range include {
+			match, err := path.Match(pattern, r.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid include pattern %q: %v", pattern, err)
+			}
+
+			if match {
+				included = true
+				break
+			}
+		}
+
+		if !included {
+			continue
+		}
+
+		excluded := false
+		for _, pattern := range exclude {
+			match, err := path.Match(pattern, r.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclude pattern %q: %v", pattern, err)
+			}
+
+			if match {
+				excluded = true
+				break
+			}
+		}
+
+		if excluded {
+			continue
+		}
+
+		targets = append(targets, target{owner: r.Owner, repository: r.Name})
+	}
+
+	return targets, nil
+}
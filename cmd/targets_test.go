@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/eveldcorp/devrel-github/database"
+)
+
+func TestParseTargets(t *testing.T) {
+	cases := []struct {
+		name    string
+		args    []string
+		want    []target
+		wantErr bool
+	}{
+		{
+			name: "legacy owner and repository",
+			args: []string{"hashicorp", "terraform"},
+			want: []target{{owner: "hashicorp", repository: "terraform"}},
+		},
+		{
+			name: "owner/repository pairs",
+			args: []string{"hashicorp/terraform", "hashicorp/vault"},
+			want: []target{
+				{owner: "hashicorp", repository: "terraform"},
+				{owner: "hashicorp", repository: "vault"},
+			},
+		},
+		{
+			name: "duplicates differing in case",
+			args: []string{"hashicorp/terraform", "HashiCorp/Terraform"},
+			want: []target{{owner: "hashicorp", repository: "terraform"}},
+		},
+		{
+			name:    "single name",
+			args:    []string{"terraform"},
+			wantErr: true,
+		},
+		{
+			name:    "empty owner",
+			args:    []string{"/terraform"},
+			wantErr: true,
+		},
+		{
+			name:    "too many parts",
+			args:    []string{"hashicorp/terraform/main"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parseTargets(c.args)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFilterRepositories(t *testing.T) {
+	repositories := []database.Repository{
+		{Owner: "hashicorp", Name: "terraform"},
+		{Owner: "hashicorp", Name: "terraform-provider-aws"},
+		{Owner: "hashicorp", Name: "vault", IsArchived: true},
+		{Owner: "hashicorp", Name: "consul", IsFork: true},
+	}
+
+	cases := []struct {
+		name         string
+		include      []string
+		exclude      []string
+		skipArchived bool
+		skipForks    bool
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name: "everything",
+			want: []string{"terraform", "terraform-provider-aws", "vault", "consul"},
+		},
+		{
+			name:         "skip archived and forks",
+			skipArchived: true,
+			skipForks:    true,
+			want:         []string{"terraform", "terraform-provider-aws"},
+		},
+		{
+			name:    "include pattern",
+			include: []string{"terraform*"},
+			want:    []string{"terraform", "terraform-provider-aws"},
+		},
+		{
+			name:    "exclude wins over include",
+			include: []string{"terraform*"},
+			exclude: []string{"*-provider-*"},
+			want:    []string{"terraform"},
+		},
+		{
+			name:    "invalid pattern",
+			include: []string{"["},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			targets, err := filterRepositories(repositories, c.include, c.exclude, c.skipArchived, c.skipForks)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", targets)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := []string{}
+			for _, target := range targets {
+				if target.owner != "hashicorp" {
+					t.Errorf("unexpected owner %q", target.owner)
+				}
+				got = append(got, target.repository)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/database"
+	"github.com/spf13/cobra"
+)
+
+// Query the profiles of these logins instead of the users seen in repositories.
+var logins []string
+
+var usersCmd = &cobra.Command{
+	Use:   "users [owner/name...]",
+	Short: "Queries the profiles of the users seen in one or more repositories at owner/name",
+	Args:  cobra.ArbitraryArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if len(logins) > 0 {
+			setup(cmd)
+			return
+		}
+
+		rootCmd.PersistentPreRun(cmd, args)
+
+		if format != "sql" {
+			logger.Error("users of repositories are read from the database, use --format sql or pass --logins")
+			os.Exit(1)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		openOutput()
+		ok := true
+		if len(logins) > 0 {
+			// The types of the logins are not known.
+			query := []database.Login{}
+			for _, l := range logins {
+				query = append(query, database.Login{Login: l})
+			}
+
+			err := writeUsers(query)
+			if err != nil {
+				logger.Error("could not query users", "error", err)
+				ok = false
+			}
+		} else {
+			ok = forEachTarget(targets, queryUsers)
+		}
+		closeOutput()
+
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// Query the profiles of the users seen in the stored data of a single
+// repository, that have not been collected within the refresh interval.
+func queryUsers(t target) error {
+	stale, err := db.GetStaleLogins(gh.Host(), t.owner, t.repository, time.Now().UTC().Add(-cfg.UsersRefresh))
+	if err != nil {
+		return fmt.Errorf("could not query logins: %v", err)
+	}
+
+	if t.limit > 0 && len(stale) > t.limit {
+		stale = stale[:t.limit]
+	}
+
+	logger.Debug("Users to refresh", "owner", t.owner, "repository", t.repository, "users", len(stale))
+
+	return writeUsers(stale)
+}
+
+// Query the profiles of logins, writing every page of profiles to the output
+// as soon as it has been queried.
+func writeUsers(logins []database.Login) error {
+	if excludeBots {
+		// Do not query the profiles of logins that are known to be bots.
+		humans := []database.Login{}
+		for _, l := range logins {
+			if !l.Bot && !isBot(l.Login) {
+				humans = append(humans, l)
+			}
+		}
+		logins = humans
+	}
+
+	err := gh.QueryUsers(logins, func(users []database.User) error {
+		users = excludeUserBots(users)
+
+		if format != "sql" {
+			// Output the users as JSON.
+			for _, u := range users {
+				err := jsonOutput.Write(u)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		// Write the page of users to the database.
+		err := db.SaveUsers(users)
+		if err != nil {
+			return fmt.Errorf("could not add users to database: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not query users: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// The affiliation of logins that match no organization.
+const Community = "community"
+
+// Affiliations maps logins to the organizations they are affiliated with.
+type Affiliations struct {
+	Organizations []Organization `mapstructure:"organizations"`
+	People        []Person       `mapstructure:"people"`
+}
+
+// Organization holds how to recognize the people of an organization, and what
+// they are to the project, e.g. employee or partner.
+type Organization struct {
+	Name        string   `mapstructure:"name"`
+	Affiliation string   `mapstructure:"affiliation"`
+	GitHub      []string `mapstructure:"github"`
+	Domains     []string `mapstructure:"domains"`
+	Companies   []string `mapstructure:"companies"`
+}
+
+// Person maps a login to organizations explicitly, over time.
+type Person struct {
+	Login        string              `mapstructure:"login"`
+	Affiliations []PersonAffiliation `mapstructure:"affiliations"`
+}
+
+// PersonAffiliation holds an organization of a person from one date until
+// another, an empty date leaves the range open.
+type PersonAffiliation struct {
+	Organization string `mapstructure:"organization"`
+	From         string `mapstructure:"from"`
+	Until        string `mapstructure:"until"`
+}
+
+// LoadAffiliations loads the YAML or HCL mapping file of affiliations.
+func LoadAffiliations(path string) (*Affiliations, error) {
+	file := viper.New()
+	file.SetConfigFile(path)
+	err := file.ReadInConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not read affiliations file: %v", err)
+	}
+
+	mapping := viper.New()
+	err = mapping.MergeConfigMap(unwrapBlocks(file.AllSettings()))
+	if err != nil {
+		return nil, fmt.Errorf("could not read affiliations file: %v", err)
+	}
+
+	var affiliations Affiliations
+	err = mapping.Unmarshal(&affiliations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid affiliations file: %v", err)
+	}
+
+	err = affiliations.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &affiliations, nil
+}
+
+// Organization returns the organization with the name, ok is false when there
+// is none.
+func (a Affiliations) Organization(name string) (Organization, bool) {
+	for _, o := range a.Organizations {
+		if strings.EqualFold(o.Name, name) {
+			return o, true
+		}
+	}
+
+	return Organization{}, false
+}
+
+// FromDate returns the parsed from date, zero when the range is open.
+func (p PersonAffiliation) FromDate() time.Time {
+	from, _ := time.Parse("2006-01-02", p.From)
+	return from
+}
+
+// UntilDate returns the parsed until date, zero when the range is open.
+func (p PersonAffiliation) UntilDate() time.Time {
+	until, _ := time.Parse("2006-01-02", p.Until)
+	return until
+}
+
+func (a Affiliations) validate() error {
+	for _, o := range a.Organizations {
+		if o.Name == "" {
+			return fmt.Errorf("each organization needs a name")
+		}
+
+		if o.Affiliation == "" {
+			return fmt.Errorf("organization %q needs an affiliation, e.g. employee or partner", o.Name)
+		}
+	}
+
+	for _, p := range a.People {
+		if p.Login == "" {
+			return fmt.Errorf("each person needs a login")
+		}
+
+		for _, pa := range p.Affiliations {
+			if _, ok := a.Organization(pa.Organization); !ok {
+				return fmt.Errorf("person %q is affiliated with unknown organization %q", p.Login, pa.Organization)
+			}
+
+			for _, d := range []string{pa.From, pa.Until} {
+				if d == "" {
+					continue
+				}
+
+				_, err := time.Parse("2006-01-02", d)
+				if err != nil {
+					return fmt.Errorf("invalid date of person %q: %v", p.Login, err)
+				}
+			}
+
+			if pa.From != "" && pa.Until != "" && pa.UntilDate().Before(pa.FromDate()) {
+				return fmt.Errorf("affiliation of person %q ends before it starts", p.Login)
+			}
+		}
+	}
+
+	return nil
+}
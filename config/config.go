@@ -1,21 +1,86 @@
 package config
 
 import (
+	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// The kinds of data that can be collected for a repository.
+var Kinds = []string{"issues", "pullrequests", "discussions", "commits", "releases", "labels", "milestones", "metrics", "users"}
+
 // Config holds the configuration values for the backend.
 type Config struct {
 	DBmaxopenconns    int
 	DBconnmaxlifetime time.Duration
+	DBautomigrate     bool
 	GitHubToken       string
+	GitHubTokens      []string
+	GitHubApp         GitHubApp
+
+	// The GitHub Enterprise Server to query instead of github.com.
+	GitHubURL        string
+	GitHubRESTURL    string
+	GitHubGraphQLURL string
+	GitHubCABundle   string
+	GitHubProxy      string
+
+	Output       Output
+	Repositories []Repository
+
+	// The periods of the traffic breakdown, day and or week.
+	TrafficPeriods []string
+
+	// The branches to collect commits of besides the default branch.
+	CommitBranches []string
+
+	// How long the profile of a user is kept before it is queried again.
+	UsersRefresh time.Duration
+
+	// The glob patterns of the logins and names of bots.
+	BotPatterns []string
+
+	// The mapping file of logins to organizations.
+	AffiliationsFile string
+
+	// The cron schedule of each kind of data for the serve command.
+	Schedules map[string]string
+	Jitter    time.Duration
 }
 
-// New loads the config file into the Config struct.
-func New() *Config {
+// GitHubApp holds the credentials of a GitHub App installation.
+type GitHubApp struct {
+	ID             int64
+	InstallationID int64
+	PrivateKey     string
+}
+
+// Output holds where the collected data is written to.
+type Output struct {
+	Format      string `mapstructure:"format"`
+	Destination string `mapstructure:"destination"`
+}
+
+// Repository holds the settings for a single repository, or for every
+// repository of an organization.
+type Repository struct {
+	Repository   string   `mapstructure:"repository"`
+	Organization string   `mapstructure:"organization"`
+	Include      []string `mapstructure:"include"`
+	Exclude      []string `mapstructure:"exclude"`
+	SkipArchived *bool    `mapstructure:"skip_archived"`
+	SkipForks    *bool    `mapstructure:"skip_forks"`
+	Collect      []string `mapstructure:"collect"`
+	Limit        int      `mapstructure:"limit"`
+	Since        string   `mapstructure:"since"`
+}
+
+// New loads the config file into the Config struct. Without a path only the
+// environment is read.
+func New(path string) (*Config, error) {
 	config := viper.New()
 	replacer := strings.NewReplacer(".", "_")
 	config.SetEnvKeyReplacer(replacer)
@@ -23,11 +88,245 @@ func New() *Config {
 
 	config.SetDefault("postgres.maxopenconns", 15)
 	config.SetDefault("postgres.connmaxlifetime", 15*time.Minute)
+	config.SetDefault("postgres.automigrate", false)
 	config.SetDefault("github.token", "")
+	config.SetDefault("github.tokens", []string{})
+	config.SetDefault("github.app.id", 0)
+	config.SetDefault("github.app.installation_id", 0)
+	config.SetDefault("github.app.private_key", "")
+	config.SetDefault("github.url", "")
+	config.SetDefault("github.rest_url", "")
+	config.SetDefault("github.graphql_url", "")
+	config.SetDefault("github.ca_bundle", "")
+	config.SetDefault("github.proxy", "")
+	config.SetDefault("output.format", "")
+	config.SetDefault("output.destination", "")
+	config.SetDefault("metrics.periods", []string{"day"})
+	config.SetDefault("commits.branches", []string{})
+	config.SetDefault("users.refresh", 7*24*time.Hour)
+	config.SetDefault("affiliations.file", "")
+	config.SetDefault("bots.patterns", []string{`*\[bot\]`})
+	config.SetDefault("serve.jitter", 5*time.Minute)
+	config.SetDefault("serve.schedules.issues", "@hourly")
+	config.SetDefault("serve.schedules.pullrequests", "@hourly")
+	config.SetDefault("serve.schedules.discussions", "@hourly")
+	config.SetDefault("serve.schedules.commits", "@hourly")
+	config.SetDefault("serve.schedules.releases", "@daily")
+	config.SetDefault("serve.schedules.labels", "@daily")
+	config.SetDefault("serve.schedules.milestones", "@hourly")
+	config.SetDefault("serve.schedules.metrics", "@daily")
+	config.SetDefault("serve.schedules.users", "@daily")
+
+	if path != "" {
+		file := viper.New()
+		file.SetConfigFile(path)
+		err := file.ReadInConfig()
+		if err != nil {
+			return nil, fmt.Errorf("could not read config file: %v", err)
+		}
+
+		err = config.MergeConfigMap(unwrapBlocks(file.AllSettings()))
+		if err != nil {
+			return nil, fmt.Errorf("could not read config file: %v", err)
+		}
+	}
+
+	token, err := resolve(config.GetString("github.token"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid github.token: %v", err)
+	}
+
+	tokens := []string{}
+	for _, t := range config.GetStringSlice("github.tokens") {
+		token, err := resolve(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid github.tokens: %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	privateKey, err := resolve(config.GetString("github.app.private_key"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid github.app.private_key: %v", err)
+	}
+
+	if config.GetInt64("github.app.id") != 0 && (config.GetInt64("github.app.installation_id") == 0 || privateKey == "") {
+		return nil, fmt.Errorf("github.app needs an installation_id and a private_key")
+	}
+
+	proxy, err := resolve(config.GetString("github.proxy"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid github.proxy: %v", err)
+	}
+
+	destination, err := resolve(config.GetString("output.destination"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid output.destination: %v", err)
+	}
+
+	var repositories []Repository
+	err = config.UnmarshalKey("repositories", &repositories)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repositories: %v", err)
+	}
+
+	for _, r := range repositories {
+		err := r.validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	periods := config.GetStringSlice("metrics.periods")
+	for _, p := range periods {
+		if p != "day" && p != "week" {
+			return nil, fmt.Errorf("invalid metrics.periods: %q is not day or week", p)
+		}
+	}
+
+	schedules := map[string]string{}
+	for _, k := range Kinds {
+		schedules[k] = config.GetString("serve.schedules." + k)
+	}
 
 	return &Config{
 		DBmaxopenconns:    config.GetInt("postgres.maxopenconns"),
 		DBconnmaxlifetime: config.GetDuration("postgres.connmaxlifetime"),
-		GitHubToken:       config.GetString("github.token"),
+		DBautomigrate:     config.GetBool("postgres.automigrate"),
+		GitHubToken:       token,
+		GitHubTokens:      tokens,
+		GitHubApp: GitHubApp{
+			ID:             config.GetInt64("github.app.id"),
+			InstallationID: config.GetInt64("github.app.installation_id"),
+			PrivateKey:     privateKey,
+		},
+		GitHubURL:        config.GetString("github.url"),
+		GitHubRESTURL:    config.GetString("github.rest_url"),
+		GitHubGraphQLURL: config.GetString("github.graphql_url"),
+		GitHubCABundle:   config.GetString("github.ca_bundle"),
+		GitHubProxy:      proxy,
+		Output: Output{
+			Format:      config.GetString("output.format"),
+			Destination: destination,
+		},
+		Repositories:     repositories,
+		TrafficPeriods:   periods,
+		CommitBranches:   config.GetStringSlice("commits.branches"),
+		UsersRefresh:     config.GetDuration("users.refresh"),
+		AffiliationsFile: config.GetString("affiliations.file"),
+		BotPatterns:      config.GetStringSlice("bots.patterns"),
+		Schedules:        schedules,
+		Jitter:           config.GetDuration("serve.jitter"),
+	}, nil
+}
+
+// Collects returns whether the kind of data should be collected for the
+// repository. An empty collect list collects everything.
+func (r Repository) Collects(kind string) bool {
+	if len(r.Collect) == 0 {
+		return true
+	}
+
+	for _, c := range r.Collect {
+		if c == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SinceTime returns the parsed since date of the repository.
+func (r Repository) SinceTime() time.Time {
+	since, _ := time.Parse(time.RFC3339, r.Since)
+	return since
+}
+
+func (r Repository) validate() error {
+	if (r.Repository == "") == (r.Organization == "") {
+		return fmt.Errorf("each repository needs either a repository or an organization")
+	}
+
+	if r.Repository != "" {
+		parts := strings.Split(r.Repository, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("repository %q is not of the form owner/repository", r.Repository)
+		}
+	}
+
+	for _, c := range r.Collect {
+		known := false
+		for _, k := range Kinds {
+			if c == k {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return fmt.Errorf("unknown kind %q to collect, can be one of %s", c, strings.Join(Kinds, ", "))
+		}
+	}
+
+	if r.Limit < 0 {
+		return fmt.Errorf("limit has to be a positive number")
+	}
+
+	if r.Since != "" {
+		_, err := time.Parse(time.RFC3339, r.Since)
+		if err != nil {
+			return fmt.Errorf("invalid time for since: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// The blocks that are lists even when there is a single one.
+var listBlocks = map[string]bool{
+	"repositories":  true,
+	"organizations": true,
+	"people":        true,
+}
+
+// HCL decodes every block into a list, unwrap the single blocks so that e.g.
+// github { token = "" } can be read as github.token.
+func unwrapBlocks(settings map[string]interface{}) map[string]interface{} {
+	for k, v := range settings {
+		if listBlocks[k] {
+			continue
+		}
+
+		if blocks, ok := v.([]map[string]interface{}); ok && len(blocks) == 1 {
+			settings[k] = unwrapBlocks(blocks[0])
+		}
+
+		if block, ok := v.(map[string]interface{}); ok {
+			settings[k] = unwrapBlocks(block)
+		}
+	}
+
+	return settings
+}
+
+// Resolve a credential reference. Values of the form env:NAME are read from
+// the environment and file:PATH from a file, anything else is used as is.
+func resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(value, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(value, "file:"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return value, nil
 	}
 }
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRepositoryValidate(t *testing.T) {
+	cases := []struct {
+		name       string
+		repository Repository
+		wantErr    bool
+	}{
+		{
+			name:       "repository",
+			repository: Repository{Repository: "hashicorp/terraform"},
+		},
+		{
+			name:       "organization",
+			repository: Repository{Organization: "hashicorp", Collect: []string{"issues", "commits"}},
+		},
+		{
+			name:       "neither repository nor organization",
+			repository: Repository{},
+			wantErr:    true,
+		},
+		{
+			name:       "both repository and organization",
+			repository: Repository{Repository: "hashicorp/terraform", Organization: "hashicorp"},
+			wantErr:    true,
+		},
+		{
+			name:       "repository without owner",
+			repository: Repository{Repository: "terraform"},
+			wantErr:    true,
+		},
+		{
+			name:       "unknown kind",
+			repository: Repository{Repository: "hashicorp/terraform", Collect: []string{"wikis"}},
+			wantErr:    true,
+		},
+		{
+			name:       "negative limit",
+			repository: Repository{Repository: "hashicorp/terraform", Limit: -1},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid since",
+			repository: Repository{Repository: "hashicorp/terraform", Since: "2023-01-31"},
+			wantErr:    true,
+		},
+		{
+			name:       "since",
+			repository: Repository{Repository: "hashicorp/terraform", Since: "2023-01-31T00:00:00Z"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.repository.validate()
+			if c.wantErr && err == nil {
+				t.Errorf("expected an error")
+			}
+			if !c.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestUnwrapBlocks(t *testing.T) {
+	settings := map[string]interface{}{
+		"github": []map[string]interface{}{
+			{
+				"token": "secret",
+				"app":   []map[string]interface{}{{"id": 1}},
+			},
+		},
+		"repositories": []map[string]interface{}{
+			{"repository": "hashicorp/terraform"},
+		},
+		"output": map[string]interface{}{
+			"format": "sql",
+		},
+	}
+
+	want := map[string]interface{}{
+		"github": map[string]interface{}{
+			"token": "secret",
+			"app":   map[string]interface{}{"id": 1},
+		},
+		"repositories": []map[string]interface{}{
+			{"repository": "hashicorp/terraform"},
+		},
+		"output": map[string]interface{}{
+			"format": "sql",
+		},
+	}
+
+	got := unwrapBlocks(settings)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	os.Setenv("DEVREL_GITHUB_TEST_TOKEN", "from-env")
+	defer os.Unsetenv("DEVREL_GITHUB_TEST_TOKEN")
+
+	file := filepath.Join(t.TempDir(), "token")
+	err := os.WriteFile(file, []byte("from-file\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "plain", want: "plain"},
+		{value: "", want: ""},
+		{value: "env:DEVREL_GITHUB_TEST_TOKEN", want: "from-env"},
+		{value: "env:DEVREL_GITHUB_TEST_MISSING", wantErr: true},
+		{value: "file:" + file, want: "from-file"},
+		{value: "file:" + file + ".missing", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			got, err := resolve(c.value)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	// The environment takes precedence over the config file.
+	if token, ok := os.LookupEnv("GITHUB_TOKEN"); ok {
+		os.Unsetenv("GITHUB_TOKEN")
+		defer os.Setenv("GITHUB_TOKEN", token)
+	}
+
+	files := map[string]string{
+		"config.yaml": `
+github:
+  token: secret
+repositories:
+  - repository: hashicorp/terraform
+    collect: [issues]
+    limit: 10
+  - organization: hashicorp
+    include: ["terraform-*"]
+serve:
+  schedules:
+    issues: "@daily"
+`,
+		"config.hcl": `
+github {
+  token = "secret"
+}
+
+repositories {
+  repository = "hashicorp/terraform"
+  collect    = ["issues"]
+  limit      = 10
+}
+
+repositories {
+  organization = "hashicorp"
+  include      = ["terraform-*"]
+}
+
+serve {
+  schedules {
+    issues = "@daily"
+  }
+}
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			err := os.WriteFile(path, []byte(content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := New(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.GitHubToken != "secret" {
+				t.Errorf("got token %q", cfg.GitHubToken)
+			}
+
+			if len(cfg.Repositories) != 2 {
+				t.Fatalf("got %d repositories, want 2", len(cfg.Repositories))
+			}
+
+			r := cfg.Repositories[0]
+			if r.Repository != "hashicorp/terraform" || r.Limit != 10 || !r.Collects("issues") || r.Collects("commits") {
+				t.Errorf("unexpected repository %+v", r)
+			}
+
+			o := cfg.Repositories[1]
+			if o.Organization != "hashicorp" || !reflect.DeepEqual(o.Include, []string{"terraform-*"}) || !o.Collects("commits") {
+				t.Errorf("unexpected organization %+v", o)
+			}
+
+			if cfg.Schedules["issues"] != "@daily" || cfg.Schedules["pullrequests"] != "@hourly" {
+				t.Errorf("unexpected schedules %v", cfg.Schedules)
+			}
+		})
+	}
+}
+
+func TestNewInvalidRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte("repositories:\n  - repository: terraform\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = New(path)
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+}
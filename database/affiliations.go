@@ -0,0 +1,98 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+type Affiliation struct {
+	Host         string     `json:"host" db:"host"`
+	Login        string     `json:"login" db:"login"`
+	Organization string     `json:"organization" db:"organization"`
+	Affiliation  string     `json:"affiliation" db:"affiliation"`
+	Source       string     `json:"source" db:"source"`
+	ValidFrom    *time.Time `json:"valid_from" db:"valid_from"`
+	ValidUntil   *time.Time `json:"valid_until" db:"valid_until"`
+}
+
+// An email address or company a login is known by.
+type Identity struct {
+	Login   string `json:"login" db:"login"`
+	Email   string `json:"email" db:"email"`
+	Company string `json:"company" db:"company"`
+}
+
+// Affiliations
+const insertAffiliation = `INSERT INTO github_affiliations (
+		host,
+		login,
+		organization,
+		affiliation,
+		source,
+		valid_from,
+		valid_until
+	)
+	VALUES (
+		:host,
+		:login,
+		:organization,
+		:affiliation,
+		:source,
+		:valid_from,
+		:valid_until
+	)`
+
+const deleteAffiliations = `DELETE FROM github_affiliations WHERE host = $1`
+
+// The emails and companies of the profiles, and the emails of the commits
+// authored by a login.
+const selectIdentities = `SELECT login, email, company FROM github_users
+	WHERE host = :host AND type <> ''
+	UNION
+	SELECT DISTINCT author, author_email, '' FROM github_commits
+	WHERE host = :host AND author <> '' AND author_email <> ''
+	ORDER BY login`
+
+// GetIdentities returns the emails and companies of every login of a host.
+func (db *DatabaseImpl) GetIdentities(host string) ([]Identity, error) {
+	identities := []Identity{}
+
+	params := map[string]interface{}{
+		"host": host,
+	}
+
+	query, err := db.client.PrepareNamed(selectIdentities)
+	if err != nil {
+		return identities, err
+	}
+	defer query.Close()
+
+	err = query.Select(&identities, params)
+	if err != nil {
+		return identities, err
+	}
+
+	return identities, nil
+}
+
+// SaveAffiliations replaces the affiliations of every login of a host in a
+// single transaction.
+func (db *DatabaseImpl) SaveAffiliations(host string, input []Affiliation) error {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(deleteAffiliations, host)
+	if err != nil {
+		return fmt.Errorf("could not delete affiliations: %v", err)
+	}
+
+	err = execBatch(tx, insertAffiliation, input)
+	if err != nil {
+		return fmt.Errorf("could not add affiliations: %v", err)
+	}
+
+	return tx.Commit()
+}
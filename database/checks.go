@@ -0,0 +1,157 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PullrequestCommit struct {
+	Pullrequest     string    `json:"-" db:"pullrequest"`
+	SHA             string    `json:"sha" db:"sha"`
+	MessageHeadline string    `json:"message_headline" db:"message_headline"`
+	Author          string    `json:"author" db:"author"`
+	AuthorName      string    `json:"author_name" db:"author_name"`
+	AuthorEmail     string    `json:"author_email" db:"author_email"`
+	AuthoredAt      time.Time `json:"authored_at" db:"authored_at"`
+	CommittedAt     time.Time `json:"committed_at" db:"committed_at"`
+}
+
+// A check run or commit status of the head commit of a pullrequest.
+type PullrequestCheck struct {
+	ID          string    `json:"id" db:"id"`
+	Pullrequest string    `json:"-" db:"pullrequest"`
+	SHA         string    `json:"sha" db:"sha"`
+	Type        string    `json:"type" db:"type"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	App         string    `json:"app" db:"app"`
+	Workflow    string    `json:"workflow" db:"workflow"`
+	Status      string    `json:"status" db:"status"`
+	Conclusion  string    `json:"conclusion" db:"conclusion"`
+	URL         string    `json:"url" db:"url"`
+	StartedAt   time.Time `json:"started_at" db:"started_at"`
+	CompletedAt time.Time `json:"completed_at" db:"completed_at"`
+	Duration    int       `json:"duration" db:"duration"`
+}
+
+// Pullrequest commits
+const insertPullrequestCommit = `INSERT INTO github_pullrequests_commits (
+		pullrequest,
+		sha,
+		message_headline,
+		author,
+		author_name,
+		author_email,
+		authored_at,
+		committed_at
+	)
+	VALUES (
+		:pullrequest,
+		:sha,
+		:message_headline,
+		:author,
+		:author_name,
+		:author_email,
+		:authored_at,
+		:committed_at
+	)`
+
+const deletePullrequestCommits = `DELETE FROM github_pullrequests_commits WHERE pullrequest = $1`
+
+// Pullrequest checks
+const insertPullrequestCheck = `INSERT INTO github_pullrequests_checks (
+		id,
+		pullrequest,
+		sha,
+		type,
+		name,
+		description,
+		app,
+		workflow,
+		status,
+		conclusion,
+		url,
+		started_at,
+		completed_at,
+		duration
+	)
+	VALUES (
+		:id,
+		:pullrequest,
+		:sha,
+		:type,
+		:name,
+		:description,
+		:app,
+		:workflow,
+		:status,
+		:conclusion,
+		:url,
+		:started_at,
+		:completed_at,
+		:duration
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		pullrequest = EXCLUDED.pullrequest,
+		sha = EXCLUDED.sha,
+		type = EXCLUDED.type,
+		name = EXCLUDED.name,
+		description = EXCLUDED.description,
+		app = EXCLUDED.app,
+		workflow = EXCLUDED.workflow,
+		status = EXCLUDED.status,
+		conclusion = EXCLUDED.conclusion,
+		url = EXCLUDED.url,
+		started_at = EXCLUDED.started_at,
+		completed_at = EXCLUDED.completed_at,
+		duration = EXCLUDED.duration`
+
+// Write the commits and checks of a pullrequest. The commits are replaced, as
+// force pushes remove commits, while the checks of earlier head commits are
+// kept to measure how often CI failed before a pullrequest was merged.
+func savePullrequestCommitsAndChecks(tx *sqlx.Tx, pullrequest string, inputCommits []PullrequestCommit, inputChecks []PullrequestCheck) error {
+	_, err := tx.Exec(deletePullrequestCommits, pullrequest)
+	if err != nil {
+		return fmt.Errorf("could not delete commits: %v", err)
+	}
+
+	// A batch may not touch the same row twice.
+	seen := map[string]bool{}
+	commits := []PullrequestCommit{}
+	for _, c := range inputCommits {
+		if seen[c.SHA] {
+			continue
+		}
+		seen[c.SHA] = true
+
+		c.Pullrequest = pullrequest
+		commits = append(commits, c)
+	}
+
+	err = execBatch(tx, insertPullrequestCommit, commits)
+	if err != nil {
+		return fmt.Errorf("could not add commits: %v", err)
+	}
+
+	seen = map[string]bool{}
+	checks := []PullrequestCheck{}
+	for _, c := range inputChecks {
+		if seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+
+		c.Pullrequest = pullrequest
+		checks = append(checks, c)
+	}
+
+	err = execBatch(tx, insertPullrequestCheck, checks)
+	if err != nil {
+		return fmt.Errorf("could not add checks: %v", err)
+	}
+
+	return nil
+}
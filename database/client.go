@@ -1,6 +1,8 @@
 package database
 
 import (
+	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
@@ -13,6 +15,7 @@ type Database interface {
 	AddIssueReaction(input IssueReaction) (IssueReaction, error)
 	AddIssueComment(input IssueComment) (IssueComment, error)
 	AddIssueCommentReaction(input IssueCommentReaction) (IssueCommentReaction, error)
+	SaveIssues(input []Issue, metadata Metadata, checkpoint Checkpoint) (Metadata, error)
 
 	AddPullrequest(input Pullrequest) (Pullrequest, error)
 	AddPullrequestReaction(input PullrequestReaction) (PullrequestReaction, error)
@@ -20,11 +23,29 @@ type Database interface {
 	AddPullrequestFile(input PullrequestFile) (PullrequestFile, error)
 	AddPullrequestComment(input PullrequestComment) (PullrequestComment, error)
 	AddPullrequestCommentReaction(input PullrequestCommentReaction) (PullrequestCommentReaction, error)
+	SavePullrequests(input []Pullrequest, metadata Metadata, checkpoint Checkpoint) (Metadata, error)
+
+	SaveDiscussions(input []Discussion, metadata Metadata, checkpoint Checkpoint) (Metadata, error)
+
+	SaveLabels(host string, owner string, repository string, input []Label) error
+	SaveMilestones(host string, owner string, repository string, input []Milestone) error
+
+	SaveCommits(input []Commit, metadata Metadata, branch string, head string, orphaned []string) (Metadata, error)
 
 	AddRelease(input Release) (Release, error)
 	AddReleaseAsset(input ReleaseAsset) (ReleaseAsset, error)
+	SaveReleases(input []Release) error
+	SaveReleasesUpdatedAt(metadata Metadata, updatedAt time.Time) (Metadata, error)
+	UpdateReleaseDownloads(input []ReleaseAsset) error
 
 	AddMetrics(input Metrics) (Metrics, error)
+	SaveMetricsHistory(input Metrics) error
+
+	GetStaleLogins(host string, owner string, repository string, before time.Time) ([]Login, error)
+	SaveUsers(input []User) error
+
+	GetIdentities(host string) ([]Identity, error)
+	SaveAffiliations(host string, input []Affiliation) error
 
 	AddTrafficClones(input TrafficClones) (TrafficClones, error)
 	AddTrafficViews(input TrafficViews) (TrafficViews, error)
@@ -32,7 +53,13 @@ type Database interface {
 	AddTrafficPath(input TrafficPath) (TrafficPath, error)
 
 	AddMetadata(metadata Metadata) (Metadata, error)
-	GetMetadata(owner string, repository string) (Metadata, error)
+	GetMetadata(host string, owner string, repository string) (Metadata, error)
+
+	GetCheckpoint(host string, owner string, repository string, kind string) (Checkpoint, error)
+	DeleteCheckpoint(host string, owner string, repository string, kind string) error
+
+	AddSchedule(input Schedule) (Schedule, error)
+	GetSchedule(kind string) (Schedule, error)
 }
 
 type DatabaseImpl struct {
@@ -40,7 +67,9 @@ type DatabaseImpl struct {
 	logger hclog.Logger
 }
 
-func New(connection string, DBmaxopenconns int, DBconnmaxlifetime time.Duration, logger hclog.Logger) (Database, error) {
+// New connects to the database and checks that its schema is up to date. With
+// autoMigrate an out of date schema is migrated instead of refused.
+func New(connection string, DBmaxopenconns int, DBconnmaxlifetime time.Duration, autoMigrate bool, logger hclog.Logger) (Database, error) {
 	logger = logger.Named("Database")
 
 	db, err := sqlx.Connect("pgx", connection)
@@ -51,8 +80,56 @@ func New(connection string, DBmaxopenconns int, DBconnmaxlifetime time.Duration,
 	db.SetMaxOpenConns(DBmaxopenconns)
 	db.SetConnMaxLifetime(DBconnmaxlifetime)
 
+	migrator, err := newMigrator(db, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		return nil, fmt.Errorf("could not query schema version: %v", err)
+	}
+
+	if version > migrator.Latest() {
+		return nil, fmt.Errorf("database schema version %d is newer than the latest known version %d", version, migrator.Latest())
+	}
+
+	if version < migrator.Latest() {
+		if !autoMigrate {
+			return nil, fmt.Errorf("database schema version %d is out of date, run migrate up to upgrade it to version %d", version, migrator.Latest())
+		}
+
+		err = migrator.Up()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &DatabaseImpl{
 		client: db,
 		logger: logger,
 	}, nil
 }
+
+// The number of rows written by a single multi-row insert, small enough to
+// stay below the limit of 65535 parameters per statement.
+const batchSize = 1000
+
+// Insert a slice of structs using multi-row inserts.
+func execBatch(tx *sqlx.Tx, query string, rows interface{}) error {
+	v := reflect.ValueOf(rows)
+
+	for i := 0; i < v.Len(); i += batchSize {
+		j := i + batchSize
+		if j > v.Len() {
+			j = v.Len()
+		}
+
+		_, err := tx.NamedExec(query, v.Slice(i, j).Interface())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Commit struct {
+	SHA             string           `json:"sha" db:"sha"`
+	Host            string           `json:"host" db:"host"`
+	Owner           string           `json:"owner" db:"owner"`
+	Repository      string           `json:"repository" db:"repository"`
+	Branch          string           `json:"branch" db:"-"`
+	URL             string           `json:"url" db:"url"`
+	MessageHeadline string           `json:"message_headline" db:"message_headline"`
+	Message         string           `json:"message" db:"message"`
+	Author          string           `json:"author" db:"author"`
+	AuthorName      string           `json:"author_name" db:"author_name"`
+	AuthorEmail     string           `json:"author_email" db:"author_email"`
+	AuthoredAt      time.Time        `json:"authored_at" db:"authored_at"`
+	Committer       string           `json:"committer" db:"committer"`
+	CommitterName   string           `json:"committer_name" db:"committer_name"`
+	CommitterEmail  string           `json:"committer_email" db:"committer_email"`
+	CommittedAt     time.Time        `json:"committed_at" db:"committed_at"`
+	Additions       int              `json:"additions" db:"additions"`
+	Deletions       int              `json:"deletions" db:"deletions"`
+	ChangedFiles    int              `json:"changed_files" db:"changed_files"`
+	Parents         int              `json:"parents" db:"parents"`
+	Signed          bool             `json:"signed" db:"signed"`
+	SignatureValid  bool             `json:"signature_valid" db:"signature_valid"`
+	SignatureState  string           `json:"signature_state" db:"signature_state"`
+	Signer          string           `json:"signer" db:"signer"`
+	IsBot           bool             `json:"is_bot" db:"is_bot"`
+	Coauthors       []CommitCoauthor `json:"coauthors" db:"-"`
+}
+
+type CommitBranch struct {
+	Host       string `json:"-" db:"host"`
+	Owner      string `json:"-" db:"owner"`
+	Repository string `json:"-" db:"repository"`
+	Branch     string `json:"branch" db:"branch"`
+	SHA        string `json:"-" db:"sha"`
+}
+
+type CommitCoauthor struct {
+	Host       string `json:"-" db:"host"`
+	Owner      string `json:"-" db:"owner"`
+	Repository string `json:"-" db:"repository"`
+	SHA        string `json:"-" db:"sha"`
+	Name       string `json:"name" db:"name"`
+	Email      string `json:"email" db:"email"`
+}
+
+// Commits
+const insertCommit = `INSERT INTO github_commits (
+		sha,
+		host,
+		owner,
+		repository,
+		url,
+		message_headline,
+		message,
+		author,
+		author_name,
+		author_email,
+		authored_at,
+		committer,
+		committer_name,
+		committer_email,
+		committed_at,
+		additions,
+		deletions,
+		changed_files,
+		parents,
+		signed,
+		signature_valid,
+		signature_state,
+		signer,
+		is_bot
+	)
+	VALUES (
+		:sha,
+		:host,
+		:owner,
+		:repository,
+		:url,
+		:message_headline,
+		:message,
+		:author,
+		:author_name,
+		:author_email,
+		:authored_at,
+		:committer,
+		:committer_name,
+		:committer_email,
+		:committed_at,
+		:additions,
+		:deletions,
+		:changed_files,
+		:parents,
+		:signed,
+		:signature_valid,
+		:signature_state,
+		:signer,
+		:is_bot
+	)
+	ON CONFLICT (host, owner, repository, sha) DO UPDATE
+	SET
+		url = EXCLUDED.url,
+		message_headline = EXCLUDED.message_headline,
+		message = EXCLUDED.message,
+		author = EXCLUDED.author,
+		author_name = EXCLUDED.author_name,
+		author_email = EXCLUDED.author_email,
+		authored_at = EXCLUDED.authored_at,
+		committer = EXCLUDED.committer,
+		committer_name = EXCLUDED.committer_name,
+		committer_email = EXCLUDED.committer_email,
+		committed_at = EXCLUDED.committed_at,
+		additions = EXCLUDED.additions,
+		deletions = EXCLUDED.deletions,
+		changed_files = EXCLUDED.changed_files,
+		parents = EXCLUDED.parents,
+		signed = EXCLUDED.signed,
+		signature_valid = EXCLUDED.signature_valid,
+		signature_state = EXCLUDED.signature_state,
+		signer = EXCLUDED.signer,
+		is_bot = EXCLUDED.is_bot`
+
+const insertCommitBranch = `INSERT INTO github_commits_branches (
+		host,
+		owner,
+		repository,
+		branch,
+		sha
+	)
+	VALUES (
+		:host,
+		:owner,
+		:repository,
+		:branch,
+		:sha
+	)
+	ON CONFLICT DO NOTHING`
+
+const insertCommitCoauthor = `INSERT INTO github_commits_coauthors (
+		host,
+		owner,
+		repository,
+		sha,
+		name,
+		email
+	)
+	VALUES (
+		:host,
+		:owner,
+		:repository,
+		:sha,
+		:name,
+		:email
+	)
+	ON CONFLICT (host, owner, repository, sha, email) DO UPDATE SET name = EXCLUDED.name`
+
+const deleteCommitBranch = `DELETE FROM github_commits_branches
+	WHERE host = $1 AND owner = $2 AND repository = $3 AND branch = $4 AND sha = $5`
+
+// Commits are deleted with their co-authors once they are on no branch.
+const deleteOrphanedCommitCoauthors = `DELETE FROM github_commits_coauthors c
+	WHERE host = $1 AND owner = $2 AND repository = $3 AND sha = $4
+	AND NOT EXISTS (
+		SELECT 1 FROM github_commits_branches b
+		WHERE b.host = c.host AND b.owner = c.owner AND b.repository = c.repository AND b.sha = c.sha
+	)`
+
+const deleteOrphanedCommit = `DELETE FROM github_commits c
+	WHERE host = $1 AND owner = $2 AND repository = $3 AND sha = $4
+	AND NOT EXISTS (
+		SELECT 1 FROM github_commits_branches b
+		WHERE b.host = c.host AND b.owner = c.owner AND b.repository = c.repository AND b.sha = c.sha
+	)`
+
+// SaveCommits writes a page of commits of a branch with their co-authors in a
+// single transaction. When head is not empty the branch has been walked
+// completely, and its head is stored in the metadata in that same transaction.
+// The orphaned commits, which a rewrite of the history removed from the branch,
+// are removed from the branch then, and deleted when they are on no other
+// branch.
+func (db *DatabaseImpl) SaveCommits(input []Commit, metadata Metadata, branch string, head string, orphaned []string) (Metadata, error) {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return metadata, err
+	}
+	defer tx.Rollback()
+
+	err = saveCommits(tx, input, branch)
+	if err != nil {
+		return metadata, err
+	}
+
+	if head != "" {
+		err = deleteOrphanedCommits(tx, metadata, branch, orphaned)
+		if err != nil {
+			return metadata, err
+		}
+	}
+
+	updated := metadata
+	if head != "" && head != metadata.CommitsHeads[branch] {
+		updated.CommitsHeads = Heads{}
+		for b, sha := range metadata.CommitsHeads {
+			updated.CommitsHeads[b] = sha
+		}
+		updated.CommitsHeads[branch] = head
+
+		err = updateMetadata(tx, "commits_heads", &updated)
+		if err != nil {
+			return metadata, fmt.Errorf("could not update metadata: %v", err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return metadata, err
+	}
+
+	return updated, nil
+}
+
+// Write commits with the branch they were found on and their co-authors.
+func saveCommits(tx *sqlx.Tx, input []Commit, branch string) error {
+	// A batch may not touch the same row twice.
+	seen := map[string]bool{}
+	commits := []Commit{}
+	branches := []CommitBranch{}
+	coauthors := []CommitCoauthor{}
+	for _, c := range input {
+		if seen[c.SHA] {
+			continue
+		}
+		seen[c.SHA] = true
+
+		commits = append(commits, c)
+		branches = append(branches, CommitBranch{
+			Host:       c.Host,
+			Owner:      c.Owner,
+			Repository: c.Repository,
+			Branch:     branch,
+			SHA:        c.SHA,
+		})
+
+		emails := map[string]bool{}
+		for _, a := range c.Coauthors {
+			if emails[a.Email] {
+				continue
+			}
+			emails[a.Email] = true
+
+			a.Host = c.Host
+			a.Owner = c.Owner
+			a.Repository = c.Repository
+			a.SHA = c.SHA
+			coauthors = append(coauthors, a)
+		}
+	}
+
+	err := execBatch(tx, insertCommit, commits)
+	if err != nil {
+		return fmt.Errorf("could not add commits: %v", err)
+	}
+
+	err = execBatch(tx, insertCommitBranch, branches)
+	if err != nil {
+		return fmt.Errorf("could not add commit branches: %v", err)
+	}
+
+	err = execBatch(tx, insertCommitCoauthor, coauthors)
+	if err != nil {
+		return fmt.Errorf("could not add commit co-authors: %v", err)
+	}
+
+	return nil
+}
+
+// Remove orphaned commits from a branch, deleting those on no other branch.
+func deleteOrphanedCommits(tx *sqlx.Tx, metadata Metadata, branch string, orphaned []string) error {
+	for _, sha := range orphaned {
+		_, err := tx.Exec(deleteCommitBranch, metadata.Host, metadata.Owner, metadata.Repository, branch, sha)
+		if err != nil {
+			return fmt.Errorf("could not remove orphaned commit from branch: %v", err)
+		}
+
+		_, err = tx.Exec(deleteOrphanedCommitCoauthors, metadata.Host, metadata.Owner, metadata.Repository, sha)
+		if err != nil {
+			return fmt.Errorf("could not delete orphaned commit co-authors: %v", err)
+		}
+
+		_, err = tx.Exec(deleteOrphanedCommit, metadata.Host, metadata.Owner, metadata.Repository, sha)
+		if err != nil {
+			return fmt.Errorf("could not delete orphaned commit: %v", err)
+		}
+	}
+
+	return nil
+}
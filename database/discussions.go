@@ -0,0 +1,396 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Discussion struct {
+	ID                string               `json:"id" db:"id"`
+	Number            int                  `json:"number" db:"number"`
+	Host              string               `json:"host" db:"host"`
+	Owner             string               `json:"owner" db:"owner"`
+	Repository        string               `json:"repository" db:"repository"`
+	Category          DiscussionCategory   `json:"category" db:"-"`
+	CategoryID        string               `json:"-" db:"category"`
+	Title             string               `json:"title" db:"title"`
+	Body              string               `json:"body" db:"body"`
+	Author            string               `json:"author" db:"author"`
+	AuthorAssociation string               `json:"author_association" db:"author_association"`
+	AuthorType        string               `json:"author_type" db:"author_type"`
+	IsBot             bool                 `json:"is_bot" db:"is_bot"`
+	CreatedAt         time.Time            `json:"created_at" db:"created_at"`
+	PublishedAt       time.Time            `json:"published_at" db:"published_at"`
+	UpdatedAt         time.Time            `json:"updated_at" db:"updated_at"`
+	LastEditedAt      time.Time            `json:"last_edited_at" db:"last_edited_at"`
+	ClosedAt          time.Time            `json:"closed_at" db:"closed_at"`
+	Closed            bool                 `json:"closed" db:"closed"`
+	Locked            bool                 `json:"locked" db:"locked"`
+	UpvoteCount       int                  `json:"upvote_count" db:"upvote_count"`
+	IsAnswered        bool                 `json:"is_answered" db:"is_answered"`
+	Answer            string               `json:"answer" db:"answer"`
+	AnswerChosenAt    time.Time            `json:"answer_chosen_at" db:"answer_chosen_at"`
+	AnswerChosenBy    string               `json:"answer_chosen_by" db:"answer_chosen_by"`
+	Labels            StringArray          `json:"labels" db:"labels"`
+	Comments          []DiscussionComment  `json:"comments" db:"-"`
+	Reactions         []DiscussionReaction `json:"reactions" db:"-"`
+	Truncated         bool                 `json:"truncated" db:"truncated"`
+}
+
+type DiscussionCategory struct {
+	ID           string    `json:"id" db:"id"`
+	Host         string    `json:"-" db:"host"`
+	Owner        string    `json:"-" db:"owner"`
+	Repository   string    `json:"-" db:"repository"`
+	Name         string    `json:"name" db:"name"`
+	Slug         string    `json:"slug" db:"slug"`
+	Emoji        string    `json:"emoji" db:"emoji"`
+	Description  string    `json:"description" db:"description"`
+	IsAnswerable bool      `json:"is_answerable" db:"is_answerable"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type DiscussionReaction struct {
+	Discussion string `json:"-" db:"discussion"`
+	Reaction   string `json:"reaction" db:"reaction"`
+	Count      int    `json:"count" db:"count"`
+}
+
+type DiscussionComment struct {
+	ID                string                      `json:"id" db:"id"`
+	Discussion        string                      `json:"-" db:"discussion"`
+	ReplyTo           string                      `json:"-" db:"reply_to"`
+	Author            string                      `json:"author" db:"author"`
+	AuthorAssociation string                      `json:"author_association" db:"author_association"`
+	AuthorType        string                      `json:"author_type" db:"author_type"`
+	IsBot             bool                        `json:"is_bot" db:"is_bot"`
+	Body              string                      `json:"body" db:"body"`
+	CreatedAt         time.Time                   `json:"created_at" db:"created_at"`
+	PublishedAt       time.Time                   `json:"published_at" db:"published_at"`
+	UpdatedAt         time.Time                   `json:"updated_at" db:"updated_at"`
+	LastEditedAt      time.Time                   `json:"last_edited_at" db:"last_edited_at"`
+	IsAnswer          bool                        `json:"is_answer" db:"is_answer"`
+	UpvoteCount       int                         `json:"upvote_count" db:"upvote_count"`
+	Reactions         []DiscussionCommentReaction `json:"reactions" db:"-"`
+	Replies           []DiscussionComment         `json:"replies" db:"-"`
+}
+
+type DiscussionCommentReaction struct {
+	Discussion string `json:"-" db:"discussion"`
+	Comment    string `json:"-" db:"comment"`
+	Content    string `json:"reaction" db:"reaction"`
+	Count      int    `json:"count" db:"count"`
+}
+
+// Discussions
+const insertDiscussionCategory = `INSERT INTO github_discussions_categories (
+		id,
+		host,
+		owner,
+		repository,
+		name,
+		slug,
+		emoji,
+		description,
+		is_answerable,
+		created_at,
+		updated_at
+	)
+	VALUES (
+		:id,
+		:host,
+		:owner,
+		:repository,
+		:name,
+		:slug,
+		:emoji,
+		:description,
+		:is_answerable,
+		:created_at,
+		:updated_at
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		name = EXCLUDED.name,
+		slug = EXCLUDED.slug,
+		emoji = EXCLUDED.emoji,
+		description = EXCLUDED.description,
+		is_answerable = EXCLUDED.is_answerable,
+		created_at = EXCLUDED.created_at,
+		updated_at = EXCLUDED.updated_at
+	RETURNING *`
+
+const insertDiscussion = `INSERT INTO github_discussions (
+		id,
+		host,
+		owner,
+		repository,
+		number,
+		category,
+		title,
+		body,
+		author,
+		author_association,
+		author_type,
+		is_bot,
+		created_at,
+		published_at,
+		updated_at,
+		last_edited_at,
+		closed_at,
+		closed,
+		locked,
+		upvote_count,
+		is_answered,
+		answer,
+		answer_chosen_at,
+		answer_chosen_by,
+		labels,
+		truncated
+	)
+	VALUES (
+		:id,
+		:host,
+		:owner,
+		:repository,
+		:number,
+		:category,
+		:title,
+		:body,
+		:author,
+		:author_association,
+		:author_type,
+		:is_bot,
+		:created_at,
+		:published_at,
+		:updated_at,
+		:last_edited_at,
+		:closed_at,
+		:closed,
+		:locked,
+		:upvote_count,
+		:is_answered,
+		:answer,
+		:answer_chosen_at,
+		:answer_chosen_by,
+		:labels,
+		:truncated
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		number = EXCLUDED.number,
+		category = EXCLUDED.category,
+		title = EXCLUDED.title,
+		body = EXCLUDED.body,
+		author = EXCLUDED.author,
+		author_association = EXCLUDED.author_association,
+		author_type = EXCLUDED.author_type,
+		is_bot = EXCLUDED.is_bot,
+		created_at = EXCLUDED.created_at,
+		published_at = EXCLUDED.published_at,
+		updated_at = EXCLUDED.updated_at,
+		last_edited_at = EXCLUDED.last_edited_at,
+		closed_at = EXCLUDED.closed_at,
+		closed = EXCLUDED.closed,
+		locked = EXCLUDED.locked,
+		upvote_count = EXCLUDED.upvote_count,
+		is_answered = EXCLUDED.is_answered,
+		answer = EXCLUDED.answer,
+		answer_chosen_at = EXCLUDED.answer_chosen_at,
+		answer_chosen_by = EXCLUDED.answer_chosen_by,
+		labels = EXCLUDED.labels,
+		truncated = EXCLUDED.truncated
+	RETURNING *`
+
+const insertDiscussionReaction = `INSERT INTO github_discussions_reactions (
+		discussion,
+		reaction,
+		count
+	)
+	VALUES (
+		:discussion,
+		:reaction,
+		:count
+	)
+	ON CONFLICT (discussion, reaction) DO UPDATE SET count = EXCLUDED.count
+	RETURNING *`
+
+const insertDiscussionComment = `INSERT INTO github_discussions_comments (
+		id,
+		discussion,
+		reply_to,
+		author,
+		author_association,
+		author_type,
+		is_bot,
+		body,
+		created_at,
+		published_at,
+		updated_at,
+		last_edited_at,
+		is_answer,
+		upvote_count
+	)
+	VALUES (
+		:id,
+		:discussion,
+		:reply_to,
+		:author,
+		:author_association,
+		:author_type,
+		:is_bot,
+		:body,
+		:created_at,
+		:published_at,
+		:updated_at,
+		:last_edited_at,
+		:is_answer,
+		:upvote_count
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		discussion = EXCLUDED.discussion,
+		reply_to = EXCLUDED.reply_to,
+		author = EXCLUDED.author,
+		author_association = EXCLUDED.author_association,
+		author_type = EXCLUDED.author_type,
+		is_bot = EXCLUDED.is_bot,
+		body = EXCLUDED.body,
+		created_at = EXCLUDED.created_at,
+		published_at = EXCLUDED.published_at,
+		updated_at = EXCLUDED.updated_at,
+		last_edited_at = EXCLUDED.last_edited_at,
+		is_answer = EXCLUDED.is_answer,
+		upvote_count = EXCLUDED.upvote_count
+	RETURNING *`
+
+const insertDiscussionCommentReaction = `INSERT INTO github_discussions_comments_reactions (
+		discussion,
+		comment,
+		reaction,
+		count
+	)
+	VALUES (
+		:discussion,
+		:comment,
+		:reaction,
+		:count
+	)
+	ON CONFLICT (discussion, comment, reaction) DO UPDATE SET count = EXCLUDED.count
+	RETURNING *`
+
+// SaveDiscussions writes a page of discussions with their categories,
+// reactions, comments, replies and comment reactions in a single transaction,
+// and advances the metadata and checkpoint in that same transaction.
+func (db *DatabaseImpl) SaveDiscussions(input []Discussion, metadata Metadata, checkpoint Checkpoint) (Metadata, error) {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return metadata, err
+	}
+	defer tx.Rollback()
+
+	updated := metadata
+	for _, d := range input {
+		err := saveDiscussion(tx, d)
+		if err != nil {
+			return metadata, fmt.Errorf("could not add discussion %d: %v", d.Number, err)
+		}
+
+		// Only complete pages advance the metadata, a partial discussion
+		// written while paginating its comments may precede unwritten
+		// discussions.
+		if checkpoint.Number == 0 && d.UpdatedAt.After(updated.DiscussionsUpdatedAt) {
+			updated.DiscussionsUpdatedAt = d.UpdatedAt
+		}
+	}
+
+	if updated.DiscussionsUpdatedAt.After(metadata.DiscussionsUpdatedAt) {
+		err = updateMetadata(tx, "discussions_updated_at", &updated)
+		if err != nil {
+			return metadata, fmt.Errorf("could not update metadata: %v", err)
+		}
+	}
+
+	_, err = tx.NamedExec(insertCheckpoint, checkpoint)
+	if err != nil {
+		return metadata, fmt.Errorf("could not update checkpoint: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return metadata, err
+	}
+
+	return updated, nil
+}
+
+// Write a discussion with its category, reactions, comments, replies and
+// comment reactions.
+func saveDiscussion(tx *sqlx.Tx, input Discussion) error {
+	category := input.Category
+	category.Host = input.Host
+	category.Owner = input.Owner
+	category.Repository = input.Repository
+
+	_, err := tx.NamedExec(insertDiscussionCategory, category)
+	if err != nil {
+		return fmt.Errorf("could not add category: %v", err)
+	}
+
+	input.CategoryID = category.ID
+	_, err = tx.NamedExec(insertDiscussion, input)
+	if err != nil {
+		return err
+	}
+
+	reactions := []DiscussionReaction{}
+	for _, r := range input.Reactions {
+		r.Discussion = input.ID
+		reactions = append(reactions, r)
+	}
+
+	err = execBatch(tx, insertDiscussionReaction, reactions)
+	if err != nil {
+		return fmt.Errorf("could not add reactions: %v", err)
+	}
+
+	comments := []DiscussionComment{}
+	commentReactions := []DiscussionCommentReaction{}
+	for _, c := range input.Comments {
+		c.Discussion = input.ID
+		comments = append(comments, c)
+
+		for _, r := range c.Replies {
+			r.Discussion = input.ID
+			r.ReplyTo = c.ID
+			comments = append(comments, r)
+
+			for _, rr := range r.Reactions {
+				rr.Discussion = input.ID
+				rr.Comment = r.ID
+				commentReactions = append(commentReactions, rr)
+			}
+		}
+
+		for _, cr := range c.Reactions {
+			cr.Discussion = input.ID
+			cr.Comment = c.ID
+			commentReactions = append(commentReactions, cr)
+		}
+	}
+
+	err = execBatch(tx, insertDiscussionComment, comments)
+	if err != nil {
+		return fmt.Errorf("could not add comments: %v", err)
+	}
+
+	err = execBatch(tx, insertDiscussionCommentReaction, commentReactions)
+	if err != nil {
+		return fmt.Errorf("could not add comment reactions: %v", err)
+	}
+
+	return nil
+}
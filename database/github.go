@@ -2,23 +2,59 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type Metadata struct {
+	Host                  string    `json:"host" db:"host"`
 	Repository            string    `json:"repository" db:"repository"`
 	Owner                 string    `json:"owner" db:"owner"`
 	IssuesUpdatedAt       time.Time `json:"issues_updated_at" db:"issues_updated_at"`
 	PullrequestsUpdatedAt time.Time `json:"pullrequests_updated_at" db:"pullrequests_updated_at"`
+	DiscussionsUpdatedAt  time.Time `json:"discussions_updated_at" db:"discussions_updated_at"`
+	CommitsHeads          Heads     `json:"commits_heads" db:"commits_heads"`
+	ReleasesUpdatedAt     time.Time `json:"releases_updated_at" db:"releases_updated_at"`
+}
+
+type Checkpoint struct {
+	Host           string    `json:"host" db:"host"`
+	Owner          string    `json:"owner" db:"owner"`
+	Repository     string    `json:"repository" db:"repository"`
+	Kind           string    `json:"kind" db:"kind"`
+	Since          time.Time `json:"since" db:"since"`
+	Cursor         string    `json:"cursor" db:"cursor"`
+	Page           int       `json:"page" db:"page"`
+	Number         int       `json:"number" db:"number"`
+	CommentsCursor string    `json:"comments_cursor" db:"comments_cursor"`
+	CommentsPage   int       `json:"comments_page" db:"comments_page"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type Schedule struct {
+	Kind      string    `json:"kind" db:"kind"`
+	LastRunAt time.Time `json:"last_run_at" db:"last_run_at"`
+}
+
+type Repository struct {
+	Owner      string `json:"owner" db:"owner"`
+	Name       string `json:"name" db:"name"`
+	IsArchived bool   `json:"is_archived" db:"is_archived"`
+	IsFork     bool   `json:"is_fork" db:"is_fork"`
 }
 
 type Issue struct {
 	ID                string          `json:"id" db:"id"`
 	Number            int             `json:"number" db:"number"`
+	Host              string          `json:"host" db:"host"`
 	Owner             string          `json:"owner" db:"owner"`
 	Repository        string          `json:"repository" db:"repository"`
 	Author            string          `json:"author" db:"author"`
 	AuthorAssociation string          `json:"author_association" db:"author_association"`
+	AuthorType        string          `json:"author_type" db:"author_type"`
+	IsBot             bool            `json:"is_bot" db:"is_bot"`
 	Title             string          `json:"title" db:"title"`
 	Body              string          `json:"body" db:"body"`
 	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
@@ -33,6 +69,11 @@ type Issue struct {
 	Comments          []IssueComment  `json:"comments" db:"-"`
 	Reactions         []IssueReaction `json:"reactions" db:"-"`
 	Labels            StringArray     `json:"labels" db:"labels"`
+	ItemLabels        []ItemLabel     `json:"-" db:"-"`
+	Milestone         string          `json:"milestone" db:"milestone"`
+	Assignees         []ItemAssignee  `json:"assignees" db:"-"`
+	Events            []TimelineEvent `json:"events" db:"-"`
+	Truncated         bool            `json:"truncated" db:"truncated"`
 }
 
 type IssueReaction struct {
@@ -51,6 +92,8 @@ type IssueComment struct {
 	PublishedAt       time.Time              `json:"published_at" db:"published_at"`
 	UpdatedAt         time.Time              `json:"updated_at" db:"updated_at"`
 	AuthorAssociation string                 `json:"author_association" db:"author_association"`
+	AuthorType        string                 `json:"author_type" db:"author_type"`
+	IsBot             bool                   `json:"is_bot" db:"is_bot"`
 	Reactions         []IssueCommentReaction `json:"reactions" db:"-"`
 }
 
@@ -62,38 +105,51 @@ type IssueCommentReaction struct {
 }
 
 type Pullrequest struct {
-	ID                string                `json:"id" db:"id"`
-	Number            int                   `json:"number" db:"number"`
-	Owner             string                `json:"owner" db:"owner"`
-	Repository        string                `json:"repository" db:"repository"`
-	Author            string                `json:"author" db:"author"`
-	AuthorAssociation string                `json:"author_association" db:"author_association"`
-	Title             string                `json:"title" db:"title"`
-	Body              string                `json:"body" db:"body"`
-	CreatedAt         time.Time             `json:"created_at" db:"created_at"`
-	ClosedAt          time.Time             `json:"closed_at" db:"closed_at"`
-	LastEditedAt      time.Time             `json:"last_edited_at" db:"last_edited_at"`
-	MergedAt          time.Time             `json:"merged_at" db:"merged_at"`
-	UpdatedAt         time.Time             `json:"updated_at" db:"updated_at"`
-	PublishedAt       time.Time             `json:"published_at" db:"published_at"`
-	Closed            bool                  `json:"closed" db:"closed"`
-	Merged            bool                  `json:"merged" db:"merged"`
-	Mergeable         string                `json:"mergeable" db:"mergeable"`
-	Locked            bool                  `json:"locked" db:"locked"`
-	Additions         int                   `json:"additions" db:"additions"`
-	Deletions         int                   `json:"deletions" db:"deletions"`
-	ChangedFiles      int                   `json:"changed_files" db:"changed_files"`
-	BaseRefName       string                `json:"base_ref_name" db:"base_ref_name"`
-	HeadRefName       string                `json:"head_ref_name" db:"head_ref_name"`
-	State             string                `json:"state" db:"state"`
-	ReviewDecision    string                `json:"review_decision" db:"review_decision"`
-	MergedBy          string                `json:"merged_by" db:"merged_by"`
-	ClosedBy          string                `json:"closed_by" db:"closed_by"`
-	Labels            StringArray           `json:"labels" db:"labels"`
-	Comments          []PullrequestComment  `json:"comments" db:"-"`
-	Reactions         []PullrequestReaction `json:"reactions" db:"-"`
-	Reviews           []PullrequestReview   `json:"reviews" db:"-"`
-	Files             []PullrequestFile     `json:"files" db:"-"`
+	ID                string                    `json:"id" db:"id"`
+	Number            int                       `json:"number" db:"number"`
+	Host              string                    `json:"host" db:"host"`
+	Owner             string                    `json:"owner" db:"owner"`
+	Repository        string                    `json:"repository" db:"repository"`
+	Author            string                    `json:"author" db:"author"`
+	AuthorAssociation string                    `json:"author_association" db:"author_association"`
+	AuthorType        string                    `json:"author_type" db:"author_type"`
+	IsBot             bool                      `json:"is_bot" db:"is_bot"`
+	Title             string                    `json:"title" db:"title"`
+	Body              string                    `json:"body" db:"body"`
+	CreatedAt         time.Time                 `json:"created_at" db:"created_at"`
+	ClosedAt          time.Time                 `json:"closed_at" db:"closed_at"`
+	LastEditedAt      time.Time                 `json:"last_edited_at" db:"last_edited_at"`
+	MergedAt          time.Time                 `json:"merged_at" db:"merged_at"`
+	UpdatedAt         time.Time                 `json:"updated_at" db:"updated_at"`
+	PublishedAt       time.Time                 `json:"published_at" db:"published_at"`
+	Closed            bool                      `json:"closed" db:"closed"`
+	Merged            bool                      `json:"merged" db:"merged"`
+	Mergeable         string                    `json:"mergeable" db:"mergeable"`
+	Locked            bool                      `json:"locked" db:"locked"`
+	Additions         int                       `json:"additions" db:"additions"`
+	Deletions         int                       `json:"deletions" db:"deletions"`
+	ChangedFiles      int                       `json:"changed_files" db:"changed_files"`
+	BaseRefName       string                    `json:"base_ref_name" db:"base_ref_name"`
+	HeadRefName       string                    `json:"head_ref_name" db:"head_ref_name"`
+	State             string                    `json:"state" db:"state"`
+	ReviewDecision    string                    `json:"review_decision" db:"review_decision"`
+	MergedBy          string                    `json:"merged_by" db:"merged_by"`
+	ClosedBy          string                    `json:"closed_by" db:"closed_by"`
+	Labels            StringArray               `json:"labels" db:"labels"`
+	ItemLabels        []ItemLabel               `json:"-" db:"-"`
+	Milestone         string                    `json:"milestone" db:"milestone"`
+	Assignees         []ItemAssignee            `json:"assignees" db:"-"`
+	Comments          []PullrequestComment      `json:"comments" db:"-"`
+	Reactions         []PullrequestReaction     `json:"reactions" db:"-"`
+	Reviews           []PullrequestReview       `json:"reviews" db:"-"`
+	Files             []PullrequestFile         `json:"files" db:"-"`
+	Events            []TimelineEvent           `json:"events" db:"-"`
+	ReviewThreads     []PullrequestReviewThread `json:"review_threads" db:"-"`
+	HeadSHA           string                    `json:"head_sha" db:"head_sha"`
+	ChecksState       string                    `json:"checks_state" db:"checks_state"`
+	Commits           []PullrequestCommit       `json:"commits" db:"-"`
+	Checks            []PullrequestCheck        `json:"checks" db:"-"`
+	Truncated         bool                      `json:"truncated" db:"truncated"`
 }
 
 type PullrequestReaction struct {
@@ -112,6 +168,8 @@ type PullrequestComment struct {
 	PublishedAt       time.Time                    `json:"published_at" db:"published_at"`
 	UpdatedAt         time.Time                    `json:"updated_at" db:"updated_at"`
 	AuthorAssociation string                       `json:"author_association" db:"author_association"`
+	AuthorType        string                       `json:"author_type" db:"author_type"`
+	IsBot             bool                         `json:"is_bot" db:"is_bot"`
 	Reactions         []PullrequestCommentReaction `json:"reactions" db:"-"`
 }
 
@@ -123,9 +181,12 @@ type PullrequestCommentReaction struct {
 }
 
 type PullrequestReview struct {
+	ID                string    `json:"id" db:"id"`
 	Pullrequest       string    `json:"-" db:"pullrequest"`
 	Author            string    `json:"author" db:"author"`
 	AuthorAssociation string    `json:"author_association" db:"author_association"`
+	AuthorType        string    `json:"author_type" db:"author_type"`
+	IsBot             bool      `json:"is_bot" db:"is_bot"`
 	Body              string    `json:"body" db:"body"`
 	State             string    `json:"state" db:"state"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
@@ -144,6 +205,7 @@ type PullrequestFile struct {
 
 type Release struct {
 	ID           string         `json:"id" db:"id"`
+	Host         string         `json:"host" db:"host"`
 	Owner        string         `json:"owner" db:"owner"`
 	Repository   string         `json:"repository" db:"repository"`
 	Name         string         `json:"name" db:"name"`
@@ -153,41 +215,57 @@ type Release struct {
 	IsPrerelease bool           `json:"is_prerelease" db:"is_prerelease"`
 	Tag          string         `json:"tag" db:"tag"`
 	Assets       []ReleaseAsset `json:"assets" db:"-"`
+	Truncated    bool           `json:"truncated" db:"truncated"`
 }
 
 type ReleaseAsset struct {
-	ID         string `json:"id" db:"id"`
-	Release    string `json:"-" db:"release"`
-	Owner      string `json:"-" db:"owner"`
-	Repository string `json:"-" db:"repository"`
-	Name       string `json:"name" db:"name"`
-	Downloads  int    `json:"downloads" db:"downloads"`
-	Size       int    `json:"size" db:"size"`
+	ID          string    `json:"id" db:"id"`
+	Release     string    `json:"-" db:"release"`
+	Host        string    `json:"-" db:"host"`
+	Owner       string    `json:"-" db:"owner"`
+	Repository  string    `json:"-" db:"repository"`
+	Name        string    `json:"name" db:"name"`
+	Downloads   int       `json:"downloads" db:"downloads"`
+	Size        int       `json:"size" db:"size"`
+	OS          string    `json:"os" db:"os"`
+	Arch        string    `json:"arch" db:"arch"`
+	Version     string    `json:"version" db:"version"`
+	CollectedAt time.Time `json:"collected_at" db:"-"`
 }
 
 type Metrics struct {
+	Host       string            `json:"host" db:"host"`
 	Owner      string            `json:"owner" db:"owner"`
 	Repository string            `json:"repository" db:"repository"`
 	Forks      StringArray       `json:"forks" db:"forks"`
 	Watches    StringArray       `json:"watches" db:"watches"`
 	Stars      StringArray       `json:"stars" db:"stars"`
-	Clones     TrafficClones     `json:"clones" db:"-"`
-	Views      TrafficViews      `json:"views" db:"-"`
+	Clones     []TrafficClones   `json:"clones" db:"-"`
+	Views      []TrafficViews    `json:"views" db:"-"`
 	Paths      []TrafficPath     `json:"paths" db:"-"`
 	Referrers  []TrafficReferrer `json:"referrers" db:"-"`
+
+	Stargazers       []Stargazer `json:"stargazers" db:"-"`
+	ForkRepositories []Fork      `json:"fork_repositories" db:"-"`
+	Watchers         []Watcher   `json:"watchers" db:"-"`
+	CollectedAt      time.Time   `json:"collected_at" db:"-"`
 }
 
 type TrafficClones struct {
+	Host       string    `json:"-" db:"host"`
 	Owner      string    `json:"-" db:"owner"`
 	Repository string    `json:"-" db:"repository"`
+	Period     string    `json:"period" db:"period"`
 	Date       time.Time `json:"date" db:"date"`
 	Count      int       `json:"count" db:"count"`
 	Uniques    int       `json:"uniques" db:"uniques"`
 }
 
 type TrafficViews struct {
+	Host       string    `json:"-" db:"host"`
 	Owner      string    `json:"-" db:"owner"`
 	Repository string    `json:"-" db:"repository"`
+	Period     string    `json:"period" db:"period"`
 	Date       time.Time `json:"date" db:"date"`
 	Count      int       `json:"count" db:"count"`
 	Uniques    int       `json:"uniques" db:"uniques"`
@@ -195,6 +273,7 @@ type TrafficViews struct {
 
 type TrafficPath struct {
 	Path       string    `json:"path" db:"path"`
+	Host       string    `json:"-" db:"host"`
 	Owner      string    `json:"-" db:"owner"`
 	Repository string    `json:"-" db:"repository"`
 	Date       time.Time `json:"date" db:"date"`
@@ -205,6 +284,7 @@ type TrafficPath struct {
 
 type TrafficReferrer struct {
 	Referrer   string    `json:"referrer" db:"referrer"`
+	Host       string    `json:"-" db:"host"`
 	Owner      string    `json:"-" db:"owner"`
 	Repository string    `json:"-" db:"repository"`
 	Date       time.Time `json:"date" db:"date"`
@@ -217,21 +297,32 @@ func (db *DatabaseImpl) AddMetadata(input Metadata) (Metadata, error) {
 	var metadata Metadata
 	query, err := db.client.PrepareNamed(
 		`INSERT INTO github_metadata (
+			host,
 			owner,
 			repository,
 			issues_updated_at,
-			pullrequests_updated_at
+			pullrequests_updated_at,
+			discussions_updated_at,
+			commits_heads,
+			releases_updated_at
 		)
 		VALUES (
+			:host,
 			:owner,
 			:repository,
 			:issues_updated_at,
-			:pullrequests_updated_at
+			:pullrequests_updated_at,
+			:discussions_updated_at,
+			:commits_heads,
+			:releases_updated_at
 		)
-		ON CONFLICT (owner, repository) DO UPDATE 
+		ON CONFLICT (host, owner, repository) DO UPDATE 
 		SET 
 			issues_updated_at = EXCLUDED.issues_updated_at, 
-			pullrequests_updated_at = EXCLUDED.pullrequests_updated_at
+			pullrequests_updated_at = EXCLUDED.pullrequests_updated_at,
+			discussions_updated_at = EXCLUDED.discussions_updated_at,
+			commits_heads = EXCLUDED.commits_heads,
+			releases_updated_at = EXCLUDED.releases_updated_at
 		RETURNING *`)
 	if err != nil {
 		return metadata, err
@@ -247,16 +338,17 @@ func (db *DatabaseImpl) AddMetadata(input Metadata) (Metadata, error) {
 	return metadata, nil
 }
 
-func (db *DatabaseImpl) GetMetadata(owner string, repository string) (Metadata, error) {
+func (db *DatabaseImpl) GetMetadata(host string, owner string, repository string) (Metadata, error) {
 	var metadata Metadata
 
 	params := map[string]interface{}{
+		"host":       host,
 		"owner":      owner,
 		"repository": repository,
 	}
 
 	query, err := db.client.PrepareNamed(
-		`SELECT * FROM github_metadata WHERE owner = :owner AND repository = :repository`)
+		`SELECT * FROM github_metadata WHERE host = :host AND owner = :owner AND repository = :repository`)
 	if err != nil {
 		return metadata, err
 	}
@@ -264,6 +356,7 @@ func (db *DatabaseImpl) GetMetadata(owner string, repository string) (Metadata,
 	err = query.Get(&metadata, params)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			metadata.Host = host
 			metadata.Owner = owner
 			metadata.Repository = repository
 			return metadata, nil
@@ -275,67 +368,262 @@ func (db *DatabaseImpl) GetMetadata(owner string, repository string) (Metadata,
 	return metadata, nil
 }
 
-// Issues
-func (db *DatabaseImpl) AddIssue(input Issue) (Issue, error) {
-	var issue Issue
+// Upsert the metadata, only updating a single column.
+const upsertMetadata = `INSERT INTO github_metadata (
+		host,
+		owner,
+		repository,
+		issues_updated_at,
+		pullrequests_updated_at,
+		discussions_updated_at,
+		commits_heads,
+		releases_updated_at
+	)
+	VALUES (
+		:host,
+		:owner,
+		:repository,
+		:issues_updated_at,
+		:pullrequests_updated_at,
+		:discussions_updated_at,
+		:commits_heads,
+		:releases_updated_at
+	)
+	ON CONFLICT (host, owner, repository) DO UPDATE
+	SET
+		%[1]s = EXCLUDED.%[1]s
+	RETURNING *`
+
+// Update the metadata with a query that only sets the column being advanced.
+func updateMetadata(tx *sqlx.Tx, column string, metadata *Metadata) error {
+	query, err := tx.PrepareNamed(fmt.Sprintf(upsertMetadata, column))
+	if err != nil {
+		return err
+	}
+	defer query.Close()
+
+	return query.Get(metadata, *metadata)
+}
+
+// Checkpoints
+const insertCheckpoint = `INSERT INTO github_checkpoints (
+		host,
+		owner,
+		repository,
+		kind,
+		since,
+		cursor,
+		page,
+		number,
+		comments_cursor,
+		comments_page,
+		updated_at
+	)
+	VALUES (
+		:host,
+		:owner,
+		:repository,
+		:kind,
+		:since,
+		:cursor,
+		:page,
+		:number,
+		:comments_cursor,
+		:comments_page,
+		NOW()
+	)
+	ON CONFLICT (host, owner, repository, kind) DO UPDATE
+	SET
+		since = EXCLUDED.since,
+		cursor = EXCLUDED.cursor,
+		page = EXCLUDED.page,
+		number = EXCLUDED.number,
+		comments_cursor = EXCLUDED.comments_cursor,
+		comments_page = EXCLUDED.comments_page,
+		updated_at = EXCLUDED.updated_at
+	RETURNING *`
+
+// GetCheckpoint returns the checkpoint of an interrupted query, or an empty
+// checkpoint when there is nothing to resume.
+func (db *DatabaseImpl) GetCheckpoint(host string, owner string, repository string, kind string) (Checkpoint, error) {
+	var checkpoint Checkpoint
+
+	params := map[string]interface{}{
+		"host":       host,
+		"owner":      owner,
+		"repository": repository,
+		"kind":       kind,
+	}
+
 	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_issues (
-			id,
-			repository,
-			owner,
-			number,
-			title,
-			body,
-			author,
-			author_association,
-			created_at,
-			published_at,
-			updated_at,
-			last_edited_at,
-			closed_at,
-			state,
-			locked,
-			closed,
-			labels,
-			closed_by
+		`SELECT * FROM github_checkpoints WHERE host = :host AND owner = :owner AND repository = :repository AND kind = :kind`)
+	if err != nil {
+		return checkpoint, err
+	}
+
+	err = query.Get(&checkpoint, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			checkpoint.Host = host
+			checkpoint.Owner = owner
+			checkpoint.Repository = repository
+			checkpoint.Kind = kind
+			return checkpoint, nil
+		}
+
+		return checkpoint, err
+	}
+
+	return checkpoint, nil
+}
+
+// DeleteCheckpoint removes the checkpoint once a query has completed.
+func (db *DatabaseImpl) DeleteCheckpoint(host string, owner string, repository string, kind string) error {
+	params := map[string]interface{}{
+		"host":       host,
+		"owner":      owner,
+		"repository": repository,
+		"kind":       kind,
+	}
+
+	_, err := db.client.NamedExec(
+		`DELETE FROM github_checkpoints WHERE host = :host AND owner = :owner AND repository = :repository AND kind = :kind`, params)
+
+	return err
+}
+
+// Schedules
+func (db *DatabaseImpl) AddSchedule(input Schedule) (Schedule, error) {
+	var schedule Schedule
+	query, err := db.client.PrepareNamed(
+		`INSERT INTO github_schedules (
+			kind,
+			last_run_at
 		)
 		VALUES (
-			:id,
-			:repository,
-			:owner,
-			:number,
-			:title,
-			:body,
-			:author,
-			:author_association,
-			:created_at,
-			:published_at,
-			:updated_at,
-			:last_edited_at,
-			:closed_at,
-			:state,
-			:locked,
-			:closed,
-			:labels,
-			:closed_by
+			:kind,
+			:last_run_at
 		)
-		ON CONFLICT (id) DO UPDATE 
+		ON CONFLICT (kind) DO UPDATE
 		SET
-			title = EXCLUDED.title,
-			body = EXCLUDED.body,
-			author = EXCLUDED.author,
-			author_association = EXCLUDED.author_association,
-			created_at = EXCLUDED.created_at,
-			published_at = EXCLUDED.published_at,
-			updated_at = EXCLUDED.updated_at,
-			last_edited_at = EXCLUDED.last_edited_at,
-			closed_at = EXCLUDED.closed_at,
-			state = EXCLUDED.state,
-			locked = EXCLUDED.locked,
-			closed = EXCLUDED.closed,
-			labels = EXCLUDED.labels,
-			closed_by = EXCLUDED.closed_by
+			last_run_at = EXCLUDED.last_run_at
 		RETURNING *`)
+	if err != nil {
+		return schedule, err
+	}
+
+	err = query.Get(&schedule, input)
+	if err != nil {
+		return schedule, err
+	}
+
+	query.Close()
+
+	return schedule, nil
+}
+
+func (db *DatabaseImpl) GetSchedule(kind string) (Schedule, error) {
+	var schedule Schedule
+
+	params := map[string]interface{}{
+		"kind": kind,
+	}
+
+	query, err := db.client.PrepareNamed(
+		`SELECT * FROM github_schedules WHERE kind = :kind`)
+	if err != nil {
+		return schedule, err
+	}
+
+	err = query.Get(&schedule, params)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			schedule.Kind = kind
+			return schedule, nil
+		}
+
+		return schedule, err
+	}
+
+	return schedule, nil
+}
+
+// Issues
+const insertIssue = `INSERT INTO github_issues (
+		id,
+		repository,
+		host,
+		owner,
+		number,
+		title,
+		body,
+		author,
+		author_association,
+		author_type,
+		is_bot,
+		created_at,
+		published_at,
+		updated_at,
+		last_edited_at,
+		closed_at,
+		state,
+		locked,
+		closed,
+		labels,
+		milestone,
+		closed_by,
+		truncated
+	)
+	VALUES (
+		:id,
+		:repository,
+		:host,
+		:owner,
+		:number,
+		:title,
+		:body,
+		:author,
+		:author_association,
+		:author_type,
+		:is_bot,
+		:created_at,
+		:published_at,
+		:updated_at,
+		:last_edited_at,
+		:closed_at,
+		:state,
+		:locked,
+		:closed,
+		:labels,
+		:milestone,
+		:closed_by,
+		:truncated
+	)
+	ON CONFLICT (id) DO UPDATE 
+	SET
+		title = EXCLUDED.title,
+		body = EXCLUDED.body,
+		author = EXCLUDED.author,
+		author_association = EXCLUDED.author_association,
+		author_type = EXCLUDED.author_type,
+		is_bot = EXCLUDED.is_bot,
+		created_at = EXCLUDED.created_at,
+		published_at = EXCLUDED.published_at,
+		updated_at = EXCLUDED.updated_at,
+		last_edited_at = EXCLUDED.last_edited_at,
+		closed_at = EXCLUDED.closed_at,
+		state = EXCLUDED.state,
+		locked = EXCLUDED.locked,
+		closed = EXCLUDED.closed,
+		labels = EXCLUDED.labels,
+		milestone = EXCLUDED.milestone,
+		closed_by = EXCLUDED.closed_by,
+		truncated = EXCLUDED.truncated
+	RETURNING *`
+
+func (db *DatabaseImpl) AddIssue(input Issue) (Issue, error) {
+	var issue Issue
+	query, err := db.client.PrepareNamed(insertIssue)
 	if err != nil {
 		return issue, err
 	}
@@ -350,21 +638,22 @@ func (db *DatabaseImpl) AddIssue(input Issue) (Issue, error) {
 	return issue, nil
 }
 
+const insertIssueReaction = `INSERT INTO github_issues_reactions (
+		issue,
+		reaction,
+		count
+	)
+	VALUES (
+		:issue,
+		:reaction,
+		:count
+	)
+	ON CONFLICT (issue, reaction) DO UPDATE SET count = EXCLUDED.count
+	RETURNING *`
+
 func (db *DatabaseImpl) AddIssueReaction(input IssueReaction) (IssueReaction, error) {
 	var reaction IssueReaction
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_issues_reactions (
-			issue,
-			reaction,
-			count
-		)
-		VALUES (
-			:issue,
-			:reaction,
-			:count
-		)
-		ON CONFLICT (issue, reaction) DO UPDATE SET count = EXCLUDED.count
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertIssueReaction)
 	if err != nil {
 		return reaction, err
 	}
@@ -379,42 +668,49 @@ func (db *DatabaseImpl) AddIssueReaction(input IssueReaction) (IssueReaction, er
 	return reaction, nil
 }
 
+const insertIssueComment = `INSERT INTO github_issues_comments (
+		id,
+		issue,
+		author,
+		author_association,
+		author_type,
+		is_bot,
+		body,
+		created_at,
+		published_at,
+		updated_at,
+		last_edited_at
+	)
+	VALUES (
+		:id,
+		:issue,
+		:author,
+		:author_association,
+		:author_type,
+		:is_bot,
+		:body,
+		:created_at,
+		:published_at,
+		:updated_at,
+		:last_edited_at
+	)
+	ON CONFLICT (id) DO UPDATE 
+	SET 
+		issue = EXCLUDED.issue, 
+		author = EXCLUDED.author, 
+		author_association = EXCLUDED.author_association,
+		author_type = EXCLUDED.author_type,
+		is_bot = EXCLUDED.is_bot,
+		body = EXCLUDED.body, 
+		created_at = EXCLUDED.created_at, 
+		published_at = EXCLUDED.published_at, 
+		updated_at = EXCLUDED.updated_at, 
+		last_edited_at = EXCLUDED.last_edited_at
+	RETURNING *`
+
 func (db *DatabaseImpl) AddIssueComment(input IssueComment) (IssueComment, error) {
 	var comment IssueComment
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_issues_comments (
-			id,
-			issue,
-			author,
-			author_association,
-			body,
-			created_at,
-			published_at,
-			updated_at,
-			last_edited_at
-		)
-		VALUES (
-			:id,
-			:issue,
-			:author,
-			:author_association,
-			:body,
-			:created_at,
-			:published_at,
-			:updated_at,
-			:last_edited_at
-		)
-		ON CONFLICT (id) DO UPDATE 
-		SET 
-			issue = EXCLUDED.issue, 
-			author = EXCLUDED.author, 
-			author_association = EXCLUDED.author_association,
-			body = EXCLUDED.body, 
-			created_at = EXCLUDED.created_at, 
-			published_at = EXCLUDED.published_at, 
-			updated_at = EXCLUDED.updated_at, 
-			last_edited_at = EXCLUDED.last_edited_at
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertIssueComment)
 	if err != nil {
 		return comment, err
 	}
@@ -429,23 +725,24 @@ func (db *DatabaseImpl) AddIssueComment(input IssueComment) (IssueComment, error
 	return comment, nil
 }
 
+const insertIssueCommentReaction = `INSERT INTO github_issues_comments_reactions (
+		issue,
+		comment,
+		reaction,
+		count
+	)
+	VALUES (
+		:issue,
+		:comment,
+		:reaction,
+		:count
+	)
+	ON CONFLICT (issue, comment, reaction) DO UPDATE SET count = EXCLUDED.count
+	RETURNING *`
+
 func (db *DatabaseImpl) AddIssueCommentReaction(input IssueCommentReaction) (IssueCommentReaction, error) {
 	var reaction IssueCommentReaction
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_issues_comments_reactions (
-			issue,
-			comment,
-			reaction,
-			count
-		)
-		VALUES (
-			:issue,
-			:comment,
-			:reaction,
-			:count
-		)
-		ON CONFLICT (issue, comment, reaction) DO UPDATE SET count = EXCLUDED.count
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertIssueCommentReaction)
 	if err != nil {
 		return reaction, err
 	}
@@ -460,97 +757,216 @@ func (db *DatabaseImpl) AddIssueCommentReaction(input IssueCommentReaction) (Iss
 	return reaction, nil
 }
 
+// SaveIssues writes a page of issues with their reactions, comments and
+// comment reactions in a single transaction, and advances the metadata and
+// checkpoint in that same transaction.
+func (db *DatabaseImpl) SaveIssues(input []Issue, metadata Metadata, checkpoint Checkpoint) (Metadata, error) {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return metadata, err
+	}
+	defer tx.Rollback()
+
+	updated := metadata
+	for _, i := range input {
+		err := saveIssue(tx, i)
+		if err != nil {
+			return metadata, fmt.Errorf("could not add issue %d: %v", i.Number, err)
+		}
+
+		// Only complete pages advance the metadata, a partial issue written
+		// while paginating its comments may precede unwritten issues.
+		if checkpoint.Number == 0 && i.UpdatedAt.After(updated.IssuesUpdatedAt) {
+			updated.IssuesUpdatedAt = i.UpdatedAt
+		}
+	}
+
+	if updated.IssuesUpdatedAt.After(metadata.IssuesUpdatedAt) {
+		err = updateMetadata(tx, "issues_updated_at", &updated)
+		if err != nil {
+			return metadata, fmt.Errorf("could not update metadata: %v", err)
+		}
+	}
+
+	_, err = tx.NamedExec(insertCheckpoint, checkpoint)
+	if err != nil {
+		return metadata, fmt.Errorf("could not update checkpoint: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return metadata, err
+	}
+
+	return updated, nil
+}
+
+// Write an issue with its reactions, comments and comment reactions.
+func saveIssue(tx *sqlx.Tx, input Issue) error {
+	_, err := tx.NamedExec(insertIssue, input)
+	if err != nil {
+		return err
+	}
+
+	reactions := []IssueReaction{}
+	for _, r := range input.Reactions {
+		r.Issue = input.ID
+		reactions = append(reactions, r)
+	}
+
+	err = execBatch(tx, insertIssueReaction, reactions)
+	if err != nil {
+		return fmt.Errorf("could not add reactions: %v", err)
+	}
+
+	comments := []IssueComment{}
+	commentReactions := []IssueCommentReaction{}
+	for _, c := range input.Comments {
+		c.Issue = input.ID
+		comments = append(comments, c)
+
+		for _, cr := range c.Reactions {
+			cr.Issue = input.ID
+			cr.Comment = c.ID
+			commentReactions = append(commentReactions, cr)
+		}
+	}
+
+	err = execBatch(tx, insertIssueComment, comments)
+	if err != nil {
+		return fmt.Errorf("could not add comments: %v", err)
+	}
+
+	err = execBatch(tx, insertIssueCommentReaction, commentReactions)
+	if err != nil {
+		return fmt.Errorf("could not add comment reactions: %v", err)
+	}
+
+	err = saveTimelineEvents(tx, input.ID, input.Host, input.Owner, input.Repository, input.Number, input.Events)
+	if err != nil {
+		return fmt.Errorf("could not add timeline events: %v", err)
+	}
+
+	err = saveItemLabelsAndAssignees(tx, input.ID, input.Host, input.Owner, input.Repository, input.Number, input.ItemLabels, input.Assignees)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Pullrequests
+const insertPullrequest = `INSERT INTO github_pullrequests (
+		id,
+		repository,
+		host,
+		owner,
+		number,
+		title,
+		body,
+		author,
+		author_association,
+		author_type,
+		is_bot,
+		created_at,
+		published_at,
+		updated_at,
+		last_edited_at,
+		closed_at,
+		state,
+		locked,
+		closed,
+		labels,
+		milestone,
+		merged_at,
+		merged,
+		mergeable,
+		additions,
+		deletions,
+		changed_files,
+		base_ref_name,
+		head_ref_name,
+		review_decision,
+		merged_by,
+		closed_by,
+		head_sha,
+		checks_state,
+		truncated
+	)
+	VALUES (
+		:id,
+		:repository,
+		:host,
+		:owner,
+		:number,
+		:title,
+		:body,
+		:author,
+		:author_association,
+		:author_type,
+		:is_bot,
+		:created_at,
+		:published_at,
+		:updated_at,
+		:last_edited_at,
+		:closed_at,
+		:state,
+		:locked,
+		:closed,
+		:labels,
+		:milestone,
+		:merged_at,
+		:merged,
+		:mergeable,
+		:additions,
+		:deletions,
+		:changed_files,
+		:base_ref_name,
+		:head_ref_name,
+		:review_decision,
+		:merged_by,
+		:closed_by,
+		:head_sha,
+		:checks_state,
+		:truncated
+	)
+	ON CONFLICT (id) DO UPDATE 
+	SET
+		title = EXCLUDED.title,
+		body = EXCLUDED.body,
+		author = EXCLUDED.author,
+		author_association = EXCLUDED.author_association,
+		author_type = EXCLUDED.author_type,
+		is_bot = EXCLUDED.is_bot,
+		created_at = EXCLUDED.created_at,
+		published_at = EXCLUDED.published_at,
+		updated_at = EXCLUDED.updated_at,
+		last_edited_at = EXCLUDED.last_edited_at,
+		closed_at = EXCLUDED.closed_at,
+		state = EXCLUDED.state,
+		locked = EXCLUDED.locked,
+		closed = EXCLUDED.closed,
+		labels = EXCLUDED.labels,
+		milestone = EXCLUDED.milestone,
+		merged_at = EXCLUDED.merged_at,
+		merged = EXCLUDED.merged,
+		mergeable = EXCLUDED.mergeable,
+		additions = EXCLUDED.additions,
+		deletions = EXCLUDED.deletions,
+		changed_files = EXCLUDED.changed_files,
+		base_ref_name = EXCLUDED.base_ref_name,
+		head_ref_name = EXCLUDED.head_ref_name,
+		review_decision = EXCLUDED.review_decision,
+		merged_by = EXCLUDED.merged_by,
+		closed_by = EXCLUDED.closed_by,
+		head_sha = EXCLUDED.head_sha,
+		checks_state = EXCLUDED.checks_state,
+		truncated = EXCLUDED.truncated
+	RETURNING *`
+
 func (db *DatabaseImpl) AddPullrequest(input Pullrequest) (Pullrequest, error) {
 	var pr Pullrequest
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_pullrequests (
-			id,
-			repository,
-			owner,
-			number,
-			title,
-			body,
-			author,
-			author_association,
-			created_at,
-			published_at,
-			updated_at,
-			last_edited_at,
-			closed_at,
-			state,
-			locked,
-			closed,
-			labels,
-			merged_at,
-			merged,
-			mergeable,
-			additions,
-			deletions,
-			changed_files,
-			base_ref_name,
-			head_ref_name,
-			review_decision,
-			merged_by,
-			closed_by
-		)
-		VALUES (
-			:id,
-			:repository,
-			:owner,
-			:number,
-			:title,
-			:body,
-			:author,
-			:author_association,
-			:created_at,
-			:published_at,
-			:updated_at,
-			:last_edited_at,
-			:closed_at,
-			:state,
-			:locked,
-			:closed,
-			:labels,
-			:merged_at,
-			:merged,
-			:mergeable,
-			:additions,
-			:deletions,
-			:changed_files,
-			:base_ref_name,
-			:head_ref_name,
-			:review_decision,
-			:merged_by,
-			:closed_by
-		)
-		ON CONFLICT (id) DO UPDATE 
-		SET
-			title = EXCLUDED.title,
-			body = EXCLUDED.body,
-			author = EXCLUDED.author,
-			author_association = EXCLUDED.author_association,
-			created_at = EXCLUDED.created_at,
-			published_at = EXCLUDED.published_at,
-			updated_at = EXCLUDED.updated_at,
-			last_edited_at = EXCLUDED.last_edited_at,
-			closed_at = EXCLUDED.closed_at,
-			state = EXCLUDED.state,
-			locked = EXCLUDED.locked,
-			closed = EXCLUDED.closed,
-			labels = EXCLUDED.labels,
-			merged_at = EXCLUDED.merged_at,
-			merged = EXCLUDED.merged,
-			mergeable = EXCLUDED.mergeable,
-			additions = EXCLUDED.additions,
-			deletions = EXCLUDED.deletions,
-			changed_files = EXCLUDED.changed_files,
-			base_ref_name = EXCLUDED.base_ref_name,
-			head_ref_name = EXCLUDED.head_ref_name,
-			review_decision = EXCLUDED.review_decision,
-			merged_by = EXCLUDED.merged_by,
-			closed_by = EXCLUDED.closed_by
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertPullrequest)
 	if err != nil {
 		return pr, err
 	}
@@ -565,21 +981,22 @@ func (db *DatabaseImpl) AddPullrequest(input Pullrequest) (Pullrequest, error) {
 	return pr, nil
 }
 
+const insertPullrequestReaction = `INSERT INTO github_pullrequests_reactions (
+		pullrequest,
+		reaction,
+		count
+	)
+	VALUES (
+		:pullrequest,
+		:reaction,
+		:count
+	)
+	ON CONFLICT (pullrequest, reaction) DO UPDATE SET count = EXCLUDED.count
+	RETURNING *`
+
 func (db *DatabaseImpl) AddPullrequestReaction(input PullrequestReaction) (PullrequestReaction, error) {
 	var reaction PullrequestReaction
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_pullrequests_reactions (
-			pullrequest,
-			reaction,
-			count
-		)
-		VALUES (
-			:pullrequest,
-			:reaction,
-			:count
-		)
-		ON CONFLICT (pullrequest, reaction) DO UPDATE SET count = EXCLUDED.count
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertPullrequestReaction)
 	if err != nil {
 		return reaction, err
 	}
@@ -594,45 +1011,59 @@ func (db *DatabaseImpl) AddPullrequestReaction(input PullrequestReaction) (Pullr
 	return reaction, nil
 }
 
+const insertPullrequestReview = `INSERT INTO github_pullrequests_reviews (
+		id,
+		pullrequest,
+		body,
+		author,
+		author_association,
+		author_type,
+		is_bot,
+		created_at,
+		published_at,
+		updated_at,
+		last_edited_at,
+		submitted_at,
+		state
+	)
+	VALUES (
+		:id,
+		:pullrequest,
+		:body,
+		:author,
+		:author_association,
+		:author_type,
+		:is_bot,
+		:created_at,
+		:published_at,
+		:updated_at,
+		:last_edited_at,
+		:submitted_at,
+		:state
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		pullrequest = EXCLUDED.pullrequest,
+		body = EXCLUDED.body,
+		author = EXCLUDED.author,
+		author_association = EXCLUDED.author_association,
+		author_type = EXCLUDED.author_type,
+		is_bot = EXCLUDED.is_bot,
+		created_at = EXCLUDED.created_at,
+		published_at = EXCLUDED.published_at,
+		updated_at = EXCLUDED.updated_at,
+		last_edited_at = EXCLUDED.last_edited_at,
+		submitted_at = EXCLUDED.submitted_at,
+		state = EXCLUDED.state
+	RETURNING *`
+
+// Delete the reviews of a pullrequest that were stored once per author before
+// reviews were stored by id, they are replaced by the reviews being written.
+const deleteLegacyPullrequestReviews = `DELETE FROM github_pullrequests_reviews WHERE pullrequest = $1 AND id LIKE 'legacy:%'`
+
 func (db *DatabaseImpl) AddPullrequestReview(input PullrequestReview) (PullrequestReview, error) {
 	var review PullrequestReview
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_pullrequests_reviews (
-			pullrequest,
-			body,
-			author,
-			author_association,
-			created_at,
-			published_at,
-			updated_at,
-			last_edited_at,
-			submitted_at,
-  		state
-		)
-		VALUES (
-			:pullrequest,
-			:body,
-			:author,
-			:author_association,
-			:created_at,
-			:published_at,
-			:updated_at,
-			:last_edited_at,
-			:submitted_at,
-  		:state
-		)
-		ON CONFLICT (pullrequest, author) DO UPDATE 
-		SET 
-			pullrequest = EXCLUDED.pullrequest,
-			body = EXCLUDED.body,
-			author_association = EXCLUDED.author_association,
-			created_at = EXCLUDED.created_at,
-			published_at = EXCLUDED.published_at,
-			updated_at = EXCLUDED.updated_at,
-			last_edited_at = EXCLUDED.last_edited_at,
-			submitted_at = EXCLUDED.submitted_at,
-			state = EXCLUDED.state
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertPullrequestReview)
 	if err != nil {
 		return review, err
 	}
@@ -647,28 +1078,29 @@ func (db *DatabaseImpl) AddPullrequestReview(input PullrequestReview) (Pullreque
 	return review, nil
 }
 
+const insertPullrequestFile = `INSERT INTO github_pullrequests_files (
+		pullrequest,
+		additions,
+		deletions,
+		path
+	)
+	VALUES (
+		:pullrequest,
+		:additions,
+		:deletions,
+		:path
+	)
+	ON CONFLICT (pullrequest, path) DO UPDATE 
+	SET
+		pullrequest = EXCLUDED.pullrequest,
+		additions = EXCLUDED.additions,
+		deletions = EXCLUDED.deletions,
+		path = EXCLUDED.path
+	RETURNING *`
+
 func (db *DatabaseImpl) AddPullrequestFile(input PullrequestFile) (PullrequestFile, error) {
 	var file PullrequestFile
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_pullrequests_files (
-			pullrequest,
-			additions,
-			deletions,
-			path
-		)
-		VALUES (
-			:pullrequest,
-			:additions,
-			:deletions,
-			:path
-		)
-		ON CONFLICT (pullrequest, path) DO UPDATE 
-		SET
-			pullrequest = EXCLUDED.pullrequest,
-			additions = EXCLUDED.additions,
-			deletions = EXCLUDED.deletions,
-			path = EXCLUDED.path
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertPullrequestFile)
 	if err != nil {
 		return file, err
 	}
@@ -683,42 +1115,49 @@ func (db *DatabaseImpl) AddPullrequestFile(input PullrequestFile) (PullrequestFi
 	return file, nil
 }
 
+const insertPullrequestComment = `INSERT INTO github_pullrequests_comments (
+		id,
+		pullrequest,
+		author,
+		author_association,
+		author_type,
+		is_bot,
+		body,
+		created_at,
+		published_at,
+		updated_at,
+		last_edited_at
+	)
+	VALUES (
+		:id,
+		:pullrequest,
+		:author,
+		:author_association,
+		:author_type,
+		:is_bot,
+		:body,
+		:created_at,
+		:published_at,
+		:updated_at,
+		:last_edited_at
+	)
+	ON CONFLICT (id) DO UPDATE 
+	SET 
+		pullrequest = EXCLUDED.pullrequest, 
+		author = EXCLUDED.author, 
+		author_association = EXCLUDED.author_association,
+		author_type = EXCLUDED.author_type,
+		is_bot = EXCLUDED.is_bot,
+		body = EXCLUDED.body, 
+		created_at = EXCLUDED.created_at, 
+		published_at = EXCLUDED.published_at, 
+		updated_at = EXCLUDED.updated_at, 
+		last_edited_at = EXCLUDED.last_edited_at
+	RETURNING *`
+
 func (db *DatabaseImpl) AddPullrequestComment(input PullrequestComment) (PullrequestComment, error) {
 	var comment PullrequestComment
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_pullrequests_comments (
-			id,
-			pullrequest,
-			author,
-			author_association,
-			body,
-			created_at,
-			published_at,
-			updated_at,
-			last_edited_at
-		)
-		VALUES (
-			:id,
-			:pullrequest,
-			:author,
-			:author_association,
-			:body,
-			:created_at,
-			:published_at,
-			:updated_at,
-			:last_edited_at
-		)
-		ON CONFLICT (id) DO UPDATE 
-		SET 
-			pullrequest = EXCLUDED.pullrequest, 
-			author = EXCLUDED.author, 
-			author_association = EXCLUDED.author_association,
-			body = EXCLUDED.body, 
-			created_at = EXCLUDED.created_at, 
-			published_at = EXCLUDED.published_at, 
-			updated_at = EXCLUDED.updated_at, 
-			last_edited_at = EXCLUDED.last_edited_at
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertPullrequestComment)
 	if err != nil {
 		return comment, err
 	}
@@ -733,23 +1172,24 @@ func (db *DatabaseImpl) AddPullrequestComment(input PullrequestComment) (Pullreq
 	return comment, nil
 }
 
+const insertPullrequestCommentReaction = `INSERT INTO github_pullrequests_comments_reactions (
+		pullrequest,
+		comment,
+		reaction,
+		count
+	)
+	VALUES (
+		:pullrequest,
+		:comment,
+		:reaction,
+		:count
+	)
+	ON CONFLICT (pullrequest, comment, reaction) DO UPDATE SET count = EXCLUDED.count
+	RETURNING *`
+
 func (db *DatabaseImpl) AddPullrequestCommentReaction(input PullrequestCommentReaction) (PullrequestCommentReaction, error) {
 	var reaction PullrequestCommentReaction
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_pullrequests_comments_reactions (
-			pullrequest,
-			comment,
-			reaction,
-			count
-		)
-		VALUES (
-			:pullrequest,
-			:comment,
-			:reaction,
-			:count
-		)
-		ON CONFLICT (pullrequest, comment, reaction) DO UPDATE SET count = EXCLUDED.count
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertPullrequestCommentReaction)
 	if err != nil {
 		return reaction, err
 	}
@@ -764,40 +1204,152 @@ func (db *DatabaseImpl) AddPullrequestCommentReaction(input PullrequestCommentRe
 	return reaction, nil
 }
 
+// SavePullrequests writes a page of pullrequests with their reactions,
+// comments, comment reactions, reviews and files in a single transaction, and
+// advances the metadata and checkpoint in that same transaction.
+func (db *DatabaseImpl) SavePullrequests(input []Pullrequest, metadata Metadata, checkpoint Checkpoint) (Metadata, error) {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return metadata, err
+	}
+	defer tx.Rollback()
+
+	updated := metadata
+	for _, p := range input {
+		err := savePullrequest(tx, p)
+		if err != nil {
+			return metadata, fmt.Errorf("could not add pullrequest %d: %v", p.Number, err)
+		}
+
+		// Only complete pages advance the metadata, a partial pullrequest written
+		// while paginating its comments may precede unwritten pullrequests.
+		if checkpoint.Number == 0 && p.UpdatedAt.After(updated.PullrequestsUpdatedAt) {
+			updated.PullrequestsUpdatedAt = p.UpdatedAt
+		}
+	}
+
+	if updated.PullrequestsUpdatedAt.After(metadata.PullrequestsUpdatedAt) {
+		err = updateMetadata(tx, "pullrequests_updated_at", &updated)
+		if err != nil {
+			return metadata, fmt.Errorf("could not update metadata: %v", err)
+		}
+	}
+
+	_, err = tx.NamedExec(insertCheckpoint, checkpoint)
+	if err != nil {
+		return metadata, fmt.Errorf("could not update checkpoint: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return metadata, err
+	}
+
+	return updated, nil
+}
+
+// Write a pullrequest with its reactions, comments, comment reactions, reviews
+// and files.
+func savePullrequest(tx *sqlx.Tx, input Pullrequest) error {
+	_, err := tx.NamedExec(insertPullrequest, input)
+	if err != nil {
+		return err
+	}
+
+	reactions := []PullrequestReaction{}
+	for _, r := range input.Reactions {
+		r.Pullrequest = input.ID
+		reactions = append(reactions, r)
+	}
+
+	err = execBatch(tx, insertPullrequestReaction, reactions)
+	if err != nil {
+		return fmt.Errorf("could not add reactions: %v", err)
+	}
+
+	comments := []PullrequestComment{}
+	commentReactions := []PullrequestCommentReaction{}
+	for _, c := range input.Comments {
+		c.Pullrequest = input.ID
+		comments = append(comments, c)
+
+		for _, cr := range c.Reactions {
+			cr.Pullrequest = input.ID
+			cr.Comment = c.ID
+			commentReactions = append(commentReactions, cr)
+		}
+	}
+
+	err = execBatch(tx, insertPullrequestComment, comments)
+	if err != nil {
+		return fmt.Errorf("could not add comments: %v", err)
+	}
+
+	err = execBatch(tx, insertPullrequestCommentReaction, commentReactions)
+	if err != nil {
+		return fmt.Errorf("could not add comment reactions: %v", err)
+	}
+
+	err = saveTimelineEvents(tx, input.ID, input.Host, input.Owner, input.Repository, input.Number, input.Events)
+	if err != nil {
+		return fmt.Errorf("could not add timeline events: %v", err)
+	}
+
+	err = saveItemLabelsAndAssignees(tx, input.ID, input.Host, input.Owner, input.Repository, input.Number, input.ItemLabels, input.Assignees)
+	if err != nil {
+		return err
+	}
+
+	err = saveReviewThreads(tx, input.ID, input.ReviewThreads)
+	if err != nil {
+		return err
+	}
+
+	err = savePullrequestCommitsAndChecks(tx, input.ID, input.Commits, input.Checks)
+	if err != nil {
+		return err
+	}
+
+	// A batch may not touch the same row twice.
+	reviews := []PullrequestReview{}
+	seen := map[string]bool{}
+	for _, r := range input.Reviews {
+		if seen[r.ID] {
+			continue
+		}
+		seen[r.ID] = true
+
+		r.Pullrequest = input.ID
+		reviews = append(reviews, r)
+	}
+
+	_, err = tx.Exec(deleteLegacyPullrequestReviews, input.ID)
+	if err != nil {
+		return fmt.Errorf("could not delete legacy reviews: %v", err)
+	}
+
+	err = execBatch(tx, insertPullrequestReview, reviews)
+	if err != nil {
+		return fmt.Errorf("could not add reviews: %v", err)
+	}
+
+	files := []PullrequestFile{}
+	for _, f := range input.Files {
+		f.Pullrequest = input.ID
+		files = append(files, f)
+	}
+
+	err = execBatch(tx, insertPullrequestFile, files)
+	if err != nil {
+		return fmt.Errorf("could not add files: %v", err)
+	}
+
+	return nil
+}
+
 func (db *DatabaseImpl) AddRelease(input Release) (Release, error) {
 	var release Release
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_releases (
-			id,
-			owner,
-			repository,
-			name,
-			description,
-			url,
-			created_at,
-			is_prerelease,
-			tag
-		)
-		VALUES (
-			:id,
-			:owner,
-			:repository,
-			:name,
-			:description,
-			:url,
-			:created_at,
-			:is_prerelease,
-			:tag
-		)
-		ON CONFLICT (id) DO UPDATE 
-		SET 
-			name = EXCLUDED.name,
-			description = EXCLUDED.description,
-			url = EXCLUDED.url,
-			created_at = EXCLUDED.created_at,
-			is_prerelease = EXCLUDED.is_prerelease,
-			tag = EXCLUDED.tag
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertRelease)
 	if err != nil {
 		return release, err
 	}
@@ -814,29 +1366,7 @@ func (db *DatabaseImpl) AddRelease(input Release) (Release, error) {
 
 func (db *DatabaseImpl) AddReleaseAsset(input ReleaseAsset) (ReleaseAsset, error) {
 	var asset ReleaseAsset
-	query, err := db.client.PrepareNamed(
-		`INSERT INTO github_releases_assets (
-			id,
-			release,
-			owner,
-			repository,
-			name,
-			downloads, 
-			size
-		)
-		VALUES (
-			:id,
-			:release,
-			:owner,
-			:repository,
-			:name,
-			:downloads, 
-			:size
-		)
-		ON CONFLICT (id) DO UPDATE 
-		SET 
-			downloads = EXCLUDED.downloads
-		RETURNING *`)
+	query, err := db.client.PrepareNamed(insertReleaseAsset)
 	if err != nil {
 		return asset, err
 	}
@@ -855,6 +1385,7 @@ func (db *DatabaseImpl) AddMetrics(input Metrics) (Metrics, error) {
 	var metrics Metrics
 	query, err := db.client.PrepareNamed(
 		`INSERT INTO github_metrics (
+			host,
 			owner,
 			repository,
 			forks,
@@ -862,13 +1393,14 @@ func (db *DatabaseImpl) AddMetrics(input Metrics) (Metrics, error) {
 			stars
 		)
 		VALUES (
+			:host,
 			:owner,
 			:repository,
 			:forks,
 			:watches,
 			:stars
 		)
-		ON CONFLICT (owner, repository) DO UPDATE
+		ON CONFLICT (host, owner, repository) DO UPDATE
 		SET
 			forks = EXCLUDED.forks,
 			watches = EXCLUDED.watches,
@@ -892,20 +1424,24 @@ func (db *DatabaseImpl) AddTrafficClones(input TrafficClones) (TrafficClones, er
 	var clones TrafficClones
 	query, err := db.client.PrepareNamed(
 		`INSERT INTO github_metrics_clones (
+			host,
 			owner,
 			repository,
+			period,
 			date,
 			count,
 			uniques
 		)
 		VALUES (
+			:host,
 			:owner,
 			:repository,
+			:period,
 			:date,
 			:count,
 			:uniques
 		)
-		ON CONFLICT (owner, repository, date) DO UPDATE
+		ON CONFLICT (host, owner, repository, period, date) DO UPDATE
 		SET 
 			count = EXCLUDED.count,
 			uniques = EXCLUDED.uniques
@@ -928,20 +1464,24 @@ func (db *DatabaseImpl) AddTrafficViews(input TrafficViews) (TrafficViews, error
 	var views TrafficViews
 	query, err := db.client.PrepareNamed(
 		`INSERT INTO github_metrics_views (
+			host,
 			owner,
 			repository,
+			period,
 			date,
 			count,
 			uniques
 		)
 		VALUES (
+			:host,
 			:owner,
 			:repository,
+			:period,
 			:date,
 			:count,
 			:uniques
 		)
-		ON CONFLICT (owner, repository, date) DO UPDATE
+		ON CONFLICT (host, owner, repository, period, date) DO UPDATE
 		SET 
 			count = EXCLUDED.count,
 			uniques = EXCLUDED.uniques
@@ -964,6 +1504,7 @@ func (db *DatabaseImpl) AddTrafficReferrer(input TrafficReferrer) (TrafficReferr
 	var referrer TrafficReferrer
 	query, err := db.client.PrepareNamed(
 		`INSERT INTO github_metrics_referrers (
+			host,
 			owner,
 			repository,
 			referrer,
@@ -972,6 +1513,7 @@ func (db *DatabaseImpl) AddTrafficReferrer(input TrafficReferrer) (TrafficReferr
 			uniques
 		)
 		VALUES (
+			:host,
 			:owner,
 			:repository,
 			:referrer,
@@ -979,7 +1521,7 @@ func (db *DatabaseImpl) AddTrafficReferrer(input TrafficReferrer) (TrafficReferr
 			:count,
 			:uniques
 		)
-		ON CONFLICT (owner, repository, referrer, date) DO UPDATE
+		ON CONFLICT (host, owner, repository, referrer, date) DO UPDATE
 		SET 
 			count = EXCLUDED.count,
 			uniques = EXCLUDED.uniques
@@ -1002,6 +1544,7 @@ func (db *DatabaseImpl) AddTrafficPath(input TrafficPath) (TrafficPath, error) {
 	var path TrafficPath
 	query, err := db.client.PrepareNamed(
 		`INSERT INTO github_metrics_paths (
+			host,
 			owner,
 			repository,
 			path,
@@ -1011,6 +1554,7 @@ func (db *DatabaseImpl) AddTrafficPath(input TrafficPath) (TrafficPath, error) {
 			uniques
 		)
 		VALUES (
+			:host,
 			:owner,
 			:repository,
 			:path,
@@ -1019,7 +1563,7 @@ func (db *DatabaseImpl) AddTrafficPath(input TrafficPath) (TrafficPath, error) {
 			:count,
 			:uniques
 		)
-		ON CONFLICT (owner, repository, path, date) DO UPDATE
+		ON CONFLICT (host, owner, repository, path, date) DO UPDATE
 		SET 
 			title = EXCLUDED.title,
 			count = EXCLUDED.count,
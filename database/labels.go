@@ -0,0 +1,278 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Label struct {
+	ID          string    `json:"id" db:"id"`
+	Host        string    `json:"host" db:"host"`
+	Owner       string    `json:"owner" db:"owner"`
+	Repository  string    `json:"repository" db:"repository"`
+	Name        string    `json:"name" db:"name"`
+	Color       string    `json:"color" db:"color"`
+	Description string    `json:"description" db:"description"`
+	IsDefault   bool      `json:"is_default" db:"is_default"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type Milestone struct {
+	ID                 string    `json:"id" db:"id"`
+	Host               string    `json:"host" db:"host"`
+	Owner              string    `json:"owner" db:"owner"`
+	Repository         string    `json:"repository" db:"repository"`
+	Number             int       `json:"number" db:"number"`
+	Title              string    `json:"title" db:"title"`
+	Description        string    `json:"description" db:"description"`
+	URL                string    `json:"url" db:"url"`
+	State              string    `json:"state" db:"state"`
+	DueOn              time.Time `json:"due_on" db:"due_on"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	ClosedAt           time.Time `json:"closed_at" db:"closed_at"`
+	ProgressPercentage float64   `json:"progress_percentage" db:"progress_percentage"`
+	OpenIssues         int       `json:"open_issues" db:"open_issues"`
+	ClosedIssues       int       `json:"closed_issues" db:"closed_issues"`
+	OpenPullrequests   int       `json:"open_pullrequests" db:"open_pullrequests"`
+	ClosedPullrequests int       `json:"closed_pullrequests" db:"closed_pullrequests"`
+}
+
+// ItemLabel is a label of an issue or pullrequest.
+type ItemLabel struct {
+	Item       string `json:"-" db:"item"`
+	Host       string `json:"-" db:"host"`
+	Owner      string `json:"-" db:"owner"`
+	Repository string `json:"-" db:"repository"`
+	Number     int    `json:"-" db:"number"`
+	Label      string `json:"id" db:"label"`
+	Name       string `json:"name" db:"name"`
+}
+
+// ItemAssignee is an assignee of an issue or pullrequest.
+type ItemAssignee struct {
+	Item       string `json:"-" db:"item"`
+	Host       string `json:"-" db:"host"`
+	Owner      string `json:"-" db:"owner"`
+	Repository string `json:"-" db:"repository"`
+	Number     int    `json:"-" db:"number"`
+	Login      string `json:"login" db:"login"`
+}
+
+// Labels
+const insertLabel = `INSERT INTO github_labels (
+		id,
+		host,
+		owner,
+		repository,
+		name,
+		color,
+		description,
+		is_default,
+		created_at,
+		updated_at
+	)
+	VALUES (
+		:id,
+		:host,
+		:owner,
+		:repository,
+		:name,
+		:color,
+		:description,
+		:is_default,
+		:created_at,
+		:updated_at
+	)`
+
+const deleteLabels = `DELETE FROM github_labels WHERE host = $1 AND owner = $2 AND repository = $3`
+
+// Milestones
+const insertMilestone = `INSERT INTO github_milestones (
+		id,
+		host,
+		owner,
+		repository,
+		number,
+		title,
+		description,
+		url,
+		state,
+		due_on,
+		created_at,
+		updated_at,
+		closed_at,
+		progress_percentage,
+		open_issues,
+		closed_issues,
+		open_pullrequests,
+		closed_pullrequests
+	)
+	VALUES (
+		:id,
+		:host,
+		:owner,
+		:repository,
+		:number,
+		:title,
+		:description,
+		:url,
+		:state,
+		:due_on,
+		:created_at,
+		:updated_at,
+		:closed_at,
+		:progress_percentage,
+		:open_issues,
+		:closed_issues,
+		:open_pullrequests,
+		:closed_pullrequests
+	)`
+
+const deleteMilestones = `DELETE FROM github_milestones WHERE host = $1 AND owner = $2 AND repository = $3`
+
+// Labels and assignees of issues and pullrequests
+const insertItemLabel = `INSERT INTO github_items_labels (
+		item,
+		host,
+		owner,
+		repository,
+		number,
+		label,
+		name
+	)
+	VALUES (
+		:item,
+		:host,
+		:owner,
+		:repository,
+		:number,
+		:label,
+		:name
+	)`
+
+const deleteItemLabels = `DELETE FROM github_items_labels WHERE item = $1`
+
+const insertItemAssignee = `INSERT INTO github_items_assignees (
+		item,
+		host,
+		owner,
+		repository,
+		number,
+		login
+	)
+	VALUES (
+		:item,
+		:host,
+		:owner,
+		:repository,
+		:number,
+		:login
+	)`
+
+const deleteItemAssignees = `DELETE FROM github_items_assignees WHERE item = $1`
+
+// SaveLabels replaces the labels of a repository in a single transaction, so
+// that deleted labels are removed.
+func (db *DatabaseImpl) SaveLabels(host string, owner string, repository string, input []Label) error {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(deleteLabels, host, owner, repository)
+	if err != nil {
+		return fmt.Errorf("could not delete labels: %v", err)
+	}
+
+	err = execBatch(tx, insertLabel, input)
+	if err != nil {
+		return fmt.Errorf("could not add labels: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// SaveMilestones replaces the milestones of a repository in a single
+// transaction, so that deleted milestones are removed.
+func (db *DatabaseImpl) SaveMilestones(host string, owner string, repository string, input []Milestone) error {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(deleteMilestones, host, owner, repository)
+	if err != nil {
+		return fmt.Errorf("could not delete milestones: %v", err)
+	}
+
+	err = execBatch(tx, insertMilestone, input)
+	if err != nil {
+		return fmt.Errorf("could not add milestones: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// Replace the labels and assignees of an issue or pullrequest.
+func saveItemLabelsAndAssignees(tx *sqlx.Tx, item string, host string, owner string, repository string, number int, inputLabels []ItemLabel, inputAssignees []ItemAssignee) error {
+	_, err := tx.Exec(deleteItemLabels, item)
+	if err != nil {
+		return fmt.Errorf("could not delete labels: %v", err)
+	}
+
+	// A batch may not touch the same row twice.
+	seen := map[string]bool{}
+	labels := []ItemLabel{}
+	for _, l := range inputLabels {
+		if seen[l.Label] {
+			continue
+		}
+		seen[l.Label] = true
+
+		l.Item = item
+		l.Host = host
+		l.Owner = owner
+		l.Repository = repository
+		l.Number = number
+		labels = append(labels, l)
+	}
+
+	err = execBatch(tx, insertItemLabel, labels)
+	if err != nil {
+		return fmt.Errorf("could not add labels: %v", err)
+	}
+
+	_, err = tx.Exec(deleteItemAssignees, item)
+	if err != nil {
+		return fmt.Errorf("could not delete assignees: %v", err)
+	}
+
+	seen = map[string]bool{}
+	assignees := []ItemAssignee{}
+	for _, a := range inputAssignees {
+		if seen[a.Login] {
+			continue
+		}
+		seen[a.Login] = true
+
+		a.Item = item
+		a.Host = host
+		a.Owner = owner
+		a.Repository = repository
+		a.Number = number
+		assignees = append(assignees, a)
+	}
+
+	err = execBatch(tx, insertItemAssignee, assignees)
+	if err != nil {
+		return fmt.Errorf("could not add assignees: %v", err)
+	}
+
+	return nil
+}
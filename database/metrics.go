@@ -0,0 +1,265 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Stargazer struct {
+	Host       string    `json:"-" db:"host"`
+	Owner      string    `json:"-" db:"owner"`
+	Repository string    `json:"-" db:"repository"`
+	Login      string    `json:"login" db:"login"`
+	StarredAt  time.Time `json:"starred_at" db:"starred_at"`
+}
+
+type Fork struct {
+	Host       string    `json:"-" db:"host"`
+	Owner      string    `json:"-" db:"owner"`
+	Repository string    `json:"-" db:"repository"`
+	Fork       string    `json:"fork" db:"fork"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type Watcher struct {
+	Host        string    `json:"-" db:"host"`
+	Owner       string    `json:"-" db:"owner"`
+	Repository  string    `json:"-" db:"repository"`
+	Login       string    `json:"login" db:"login"`
+	FirstSeenAt time.Time `json:"first_seen_at" db:"first_seen_at"`
+}
+
+// MetricsEvent is a change of the stargazers, forks or watchers of a
+// repository. Stars and forks happen at the time GitHub reports, every other
+// event at the time the change was first observed.
+type MetricsEvent struct {
+	Host       string    `json:"host" db:"host"`
+	Owner      string    `json:"owner" db:"owner"`
+	Repository string    `json:"repository" db:"repository"`
+	Event      string    `json:"event" db:"event"`
+	Actor      string    `json:"actor" db:"actor"`
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+}
+
+// The current members of a repository, such as its stargazers, and the events
+// of members being added and removed. Reported members have the time they
+// were added from GitHub, the others the time they were first seen.
+type members struct {
+	kind     string
+	table    string
+	column   string
+	at       string
+	add      string
+	remove   string
+	reported bool
+}
+
+var (
+	stargazers = members{kind: "stargazers", table: "github_metrics_stargazers", column: "login", at: "starred_at", add: "star", remove: "unstar", reported: true}
+	forks      = members{kind: "forks", table: "github_metrics_forks", column: "fork", at: "created_at", add: "fork", remove: "unfork", reported: true}
+	watchers   = members{kind: "watchers", table: "github_metrics_watchers", column: "login", at: "first_seen_at", add: "watch", remove: "unwatch"}
+)
+
+// A member of a repository.
+type member struct {
+	Host       string    `db:"host"`
+	Owner      string    `db:"owner"`
+	Repository string    `db:"repository"`
+	Name       string    `db:"name"`
+	At         time.Time `db:"at"`
+}
+
+const insertMetricsEvent = `INSERT INTO github_metrics_events (
+		host,
+		owner,
+		repository,
+		event,
+		actor,
+		occurred_at
+	)
+	VALUES (
+		:host,
+		:owner,
+		:repository,
+		:event,
+		:actor,
+		:occurred_at
+	)
+	ON CONFLICT DO NOTHING`
+
+const selectMetricsBaseline = `SELECT COUNT(*) FROM github_metrics_baselines
+	WHERE host = $1 AND owner = $2 AND repository = $3 AND kind = $4`
+
+const insertMetricsBaseline = `INSERT INTO github_metrics_baselines (
+		host,
+		owner,
+		repository,
+		kind,
+		collected_at
+	)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT DO NOTHING`
+
+// SaveMetricsHistory replaces the stargazers, forks and watchers of a
+// repository with the collected ones in a single transaction, recording an
+// event for every stargazer, fork and watcher that was added or removed since
+// the last run.
+func (db *DatabaseImpl) SaveMetricsHistory(input Metrics) error {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current := []member{}
+	for _, s := range input.Stargazers {
+		current = append(current, member{Name: s.Login, At: s.StarredAt})
+	}
+
+	err = saveMembers(tx, stargazers, input, current)
+	if err != nil {
+		return fmt.Errorf("could not save stargazers: %v", err)
+	}
+
+	current = []member{}
+	for _, f := range input.ForkRepositories {
+		current = append(current, member{Name: f.Fork, At: f.CreatedAt})
+	}
+
+	err = saveMembers(tx, forks, input, current)
+	if err != nil {
+		return fmt.Errorf("could not save forks: %v", err)
+	}
+
+	current = []member{}
+	for _, w := range input.Watchers {
+		current = append(current, member{Name: w.Login, At: input.CollectedAt})
+	}
+
+	err = saveMembers(tx, watchers, input, current)
+	if err != nil {
+		return fmt.Errorf("could not save watchers: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// Replace the members of a repository, recording the added and removed
+// members as events. The first collection records the members as the
+// baseline, with events only for the members GitHub reports the time of, so
+// that the members the repository already had do not show up as added at the
+// time of the collection.
+func saveMembers(tx *sqlx.Tx, m members, metrics Metrics, current []member) error {
+	var baselines int
+	err := tx.Get(&baselines, selectMetricsBaseline, metrics.Host, metrics.Owner, metrics.Repository, m.kind)
+	if err != nil {
+		return err
+	}
+	baseline := baselines == 0
+
+	params := map[string]interface{}{
+		"host":       metrics.Host,
+		"owner":      metrics.Owner,
+		"repository": metrics.Repository,
+	}
+
+	query, err := tx.PrepareNamed(fmt.Sprintf(
+		`SELECT %s AS name, %s AS at FROM %s WHERE host = :host AND owner = :owner AND repository = :repository`,
+		m.column, m.at, m.table))
+	if err != nil {
+		return err
+	}
+	defer query.Close()
+
+	previous := []member{}
+	err = query.Select(&previous, params)
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	for _, p := range previous {
+		known[p.Name] = true
+	}
+
+	seen := map[string]bool{}
+	added := []member{}
+	events := []MetricsEvent{}
+	for _, c := range current {
+		if seen[c.Name] {
+			continue
+		}
+		seen[c.Name] = true
+
+		if known[c.Name] {
+			continue
+		}
+
+		c.Host = metrics.Host
+		c.Owner = metrics.Owner
+		c.Repository = metrics.Repository
+		added = append(added, c)
+
+		if baseline && (!m.reported || c.At.IsZero()) {
+			continue
+		}
+
+		events = append(events, MetricsEvent{
+			Host:       metrics.Host,
+			Owner:      metrics.Owner,
+			Repository: metrics.Repository,
+			Event:      m.add,
+			Actor:      c.Name,
+			OccurredAt: c.At,
+		})
+	}
+
+	removed := []member{}
+	for _, p := range previous {
+		if seen[p.Name] {
+			continue
+		}
+
+		p.Host = metrics.Host
+		p.Owner = metrics.Owner
+		p.Repository = metrics.Repository
+		removed = append(removed, p)
+
+		events = append(events, MetricsEvent{
+			Host:       metrics.Host,
+			Owner:      metrics.Owner,
+			Repository: metrics.Repository,
+			Event:      m.remove,
+			Actor:      p.Name,
+			OccurredAt: metrics.CollectedAt,
+		})
+	}
+
+	err = execBatch(tx, fmt.Sprintf(
+		`INSERT INTO %s (host, owner, repository, %s, %s) VALUES (:host, :owner, :repository, :name, :at)
+		ON CONFLICT DO NOTHING`,
+		m.table, m.column, m.at), added)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range removed {
+		_, err := tx.NamedExec(fmt.Sprintf(
+			`DELETE FROM %s WHERE host = :host AND owner = :owner AND repository = :repository AND %s = :name`,
+			m.table, m.column), r)
+		if err != nil {
+			return err
+		}
+	}
+
+	if baseline {
+		_, err = tx.Exec(insertMetricsBaseline, metrics.Host, metrics.Owner, metrics.Repository, m.kind, metrics.CollectedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return execBatch(tx, insertMetricsEvent, events)
+}
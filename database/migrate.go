@@ -0,0 +1,272 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+type Migrator interface {
+	Up() error
+	Down() error
+	Status() ([]MigrationStatus, error)
+	Version() (int, error)
+	Latest() int
+}
+
+type MigratorImpl struct {
+	client     *sqlx.DB
+	logger     hclog.Logger
+	migrations []Migration
+}
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int       `json:"version" db:"version"`
+	Name      string    `json:"name" db:"name"`
+	AppliedAt time.Time `json:"applied_at" db:"applied_at"`
+	Applied   bool      `json:"applied" db:"-"`
+}
+
+func NewMigrator(connection string, logger hclog.Logger) (Migrator, error) {
+	db, err := sqlx.Connect("pgx", connection)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMigrator(db, logger)
+}
+
+func newMigrator(db *sqlx.DB, logger hclog.Logger) (*MigratorImpl, error) {
+	logger = logger.Named("Migrator")
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(
+		`CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`)
+	if err != nil {
+		return nil, fmt.Errorf("could not create schema_version table: %v", err)
+	}
+
+	return &MigratorImpl{
+		client:     db,
+		logger:     logger,
+		migrations: migrations,
+	}, nil
+}
+
+// Load the embedded migrations, named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, ordered by version.
+func loadMigrations() ([]Migration, error) {
+	files, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, f := range files {
+		name := f.Name()
+
+		parts := strings.SplitN(name, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name %s", name)
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %v", name, err)
+		}
+
+		data, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version}
+			byVersion[version] = m
+		}
+
+		switch {
+		case strings.HasSuffix(parts[1], ".up.sql"):
+			m.Name = strings.TrimSuffix(parts[1], ".up.sql")
+			m.Up = string(data)
+		case strings.HasSuffix(parts[1], ".down.sql"):
+			m.Down = string(data)
+		default:
+			return nil, fmt.Errorf("migration file %s is neither up nor down", name)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d is missing its up or down file", m.Version)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Latest returns the version of the newest embedded migration.
+func (m *MigratorImpl) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the version of the last applied migration.
+func (m *MigratorImpl) Version() (int, error) {
+	var version int
+
+	err := m.client.Get(&version, `SELECT COALESCE(MAX(version), 0) FROM schema_version`)
+	if err != nil {
+		return version, err
+	}
+
+	return version, nil
+}
+
+// Up applies every migration that has not been applied yet.
+func (m *MigratorImpl) Up() error {
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		m.logger.Info("Applying migration", "version", migration.Version, "name", migration.Name)
+
+		tx, err := m.client.Beginx()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(migration.Up)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("could not apply migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO schema_version (version, name, applied_at) VALUES ($1, $2, $3)`,
+			migration.Version, migration.Name, time.Now().UTC())
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Down reverts the last applied migration.
+func (m *MigratorImpl) Down() error {
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version != current {
+			continue
+		}
+
+		m.logger.Info("Reverting migration", "version", migration.Version, "name", migration.Name)
+
+		tx, err := m.client.Beginx()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(migration.Down)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("could not revert migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+
+		_, err = tx.Exec(`DELETE FROM schema_version WHERE version = $1`, migration.Version)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		return tx.Commit()
+	}
+
+	if current == 0 {
+		return fmt.Errorf("no migration has been applied")
+	}
+
+	return fmt.Errorf("applied migration %d is unknown to this version", current)
+}
+
+// Status returns every embedded migration and whether it has been applied.
+func (m *MigratorImpl) Status() ([]MigrationStatus, error) {
+	var applied []MigrationStatus
+
+	err := m.client.Select(&applied, `SELECT version, name, applied_at FROM schema_version ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []MigrationStatus{}
+	for _, migration := range m.migrations {
+		status := MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+
+		for _, a := range applied {
+			if a.Version == migration.Version {
+				status.Applied = true
+				status.AppliedAt = a.AppliedAt
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS github_metrics_referrers;
+DROP TABLE IF EXISTS github_metrics_paths;
+DROP TABLE IF EXISTS github_metrics_views;
+DROP TABLE IF EXISTS github_metrics_clones;
+DROP TABLE IF EXISTS github_metrics;
+DROP TABLE IF EXISTS github_releases_assets;
+DROP TABLE IF EXISTS github_releases;
+DROP TABLE IF EXISTS github_pullrequests_comments_reactions;
+DROP TABLE IF EXISTS github_pullrequests_comments;
+DROP TABLE IF EXISTS github_pullrequests_reactions;
+DROP TABLE IF EXISTS github_pullrequests_files;
+DROP TABLE IF EXISTS github_pullrequests_reviews;
+DROP TABLE IF EXISTS github_pullrequests;
+DROP TABLE IF EXISTS github_issues_comments_reactions;
+DROP TABLE IF EXISTS github_issues_comments;
+DROP TABLE IF EXISTS github_issues_reactions;
+DROP TABLE IF EXISTS github_issues;
+DROP TABLE IF EXISTS github_schedules;
+DROP TABLE IF EXISTS github_metadata;
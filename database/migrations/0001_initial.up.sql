@@ -1,12 +1,3 @@
-#!/bin/bash
-set -e
-
-psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" <<-EOSQL
--- CREATE USER $POSTGRES_USER WITH PASSWORD '$POSTGRES_PASSWORD';
--- CREATE DATABASE $POSTGRES_DB;
-
-\c $POSTGRES_DB
-
 CREATE TABLE IF NOT EXISTS github_metadata (
   owner VARCHAR(255) NOT NULL,
   repository VARCHAR(255) NOT NULL,
@@ -15,6 +6,11 @@ CREATE TABLE IF NOT EXISTS github_metadata (
   PRIMARY KEY (owner, repository)
 );
 
+CREATE TABLE IF NOT EXISTS github_schedules (
+  kind VARCHAR(255) PRIMARY KEY,
+  last_run_at TIMESTAMP
+);
+
 --
 -- issues
 --
@@ -224,6 +220,3 @@ CREATE TABLE IF NOT EXISTS github_metrics_referrers (
   uniques BIGINT,
   PRIMARY KEY (repository, owner, referrer, date)
 );
-
-GRANT ALL ON ALL TABLES IN SCHEMA "public" TO $POSTGRES_USER;
-EOSQL
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE github_checkpoints (
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  kind VARCHAR(255) NOT NULL,
+  since TIMESTAMP,
+  cursor VARCHAR(255) NOT NULL,
+  page BIGINT,
+  number BIGINT,
+  comments_cursor VARCHAR(255) NOT NULL,
+  comments_page BIGINT,
+  updated_at TIMESTAMP,
+  PRIMARY KEY (owner, repository, kind)
+);
@@ -0,0 +1,32 @@
+ALTER TABLE github_metrics_referrers DROP CONSTRAINT github_metrics_referrers_pkey;
+ALTER TABLE github_metrics_referrers DROP COLUMN host;
+ALTER TABLE github_metrics_referrers ADD PRIMARY KEY (repository, owner, referrer, date);
+
+ALTER TABLE github_metrics_paths DROP CONSTRAINT github_metrics_paths_pkey;
+ALTER TABLE github_metrics_paths DROP COLUMN host;
+ALTER TABLE github_metrics_paths ADD PRIMARY KEY (repository, owner, path, date);
+
+ALTER TABLE github_metrics_views DROP CONSTRAINT github_metrics_views_pkey;
+ALTER TABLE github_metrics_views DROP COLUMN host;
+ALTER TABLE github_metrics_views ADD PRIMARY KEY (repository, owner, date);
+
+ALTER TABLE github_metrics_clones DROP CONSTRAINT github_metrics_clones_pkey;
+ALTER TABLE github_metrics_clones DROP COLUMN host;
+ALTER TABLE github_metrics_clones ADD PRIMARY KEY (repository, owner, date);
+
+ALTER TABLE github_metrics DROP CONSTRAINT github_metrics_pkey;
+ALTER TABLE github_metrics DROP COLUMN host;
+ALTER TABLE github_metrics ADD PRIMARY KEY (repository, owner);
+
+ALTER TABLE github_releases_assets DROP COLUMN host;
+ALTER TABLE github_releases DROP COLUMN host;
+ALTER TABLE github_pullrequests DROP COLUMN host;
+ALTER TABLE github_issues DROP COLUMN host;
+
+ALTER TABLE github_checkpoints DROP CONSTRAINT github_checkpoints_pkey;
+ALTER TABLE github_checkpoints DROP COLUMN host;
+ALTER TABLE github_checkpoints ADD PRIMARY KEY (owner, repository, kind);
+
+ALTER TABLE github_metadata DROP CONSTRAINT github_metadata_pkey;
+ALTER TABLE github_metadata DROP COLUMN host;
+ALTER TABLE github_metadata ADD PRIMARY KEY (owner, repository);
@@ -0,0 +1,36 @@
+--
+-- Store the host of the GitHub instance with the owner and repository, so
+-- that data from github.com and GitHub Enterprise Server can coexist.
+--
+ALTER TABLE github_metadata ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_metadata DROP CONSTRAINT github_metadata_pkey;
+ALTER TABLE github_metadata ADD PRIMARY KEY (host, owner, repository);
+
+ALTER TABLE github_checkpoints ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_checkpoints DROP CONSTRAINT github_checkpoints_pkey;
+ALTER TABLE github_checkpoints ADD PRIMARY KEY (host, owner, repository, kind);
+
+ALTER TABLE github_issues ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_pullrequests ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_releases ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_releases_assets ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+
+ALTER TABLE github_metrics ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_metrics DROP CONSTRAINT github_metrics_pkey;
+ALTER TABLE github_metrics ADD PRIMARY KEY (host, owner, repository);
+
+ALTER TABLE github_metrics_clones ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_metrics_clones DROP CONSTRAINT github_metrics_clones_pkey;
+ALTER TABLE github_metrics_clones ADD PRIMARY KEY (host, owner, repository, date);
+
+ALTER TABLE github_metrics_views ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_metrics_views DROP CONSTRAINT github_metrics_views_pkey;
+ALTER TABLE github_metrics_views ADD PRIMARY KEY (host, owner, repository, date);
+
+ALTER TABLE github_metrics_paths ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_metrics_paths DROP CONSTRAINT github_metrics_paths_pkey;
+ALTER TABLE github_metrics_paths ADD PRIMARY KEY (host, owner, repository, path, date);
+
+ALTER TABLE github_metrics_referrers ADD COLUMN host VARCHAR(255) NOT NULL DEFAULT 'github.com';
+ALTER TABLE github_metrics_referrers DROP CONSTRAINT github_metrics_referrers_pkey;
+ALTER TABLE github_metrics_referrers ADD PRIMARY KEY (host, owner, repository, referrer, date);
@@ -0,0 +1,7 @@
+DROP TABLE github_discussions_comments_reactions;
+DROP TABLE github_discussions_comments;
+DROP TABLE github_discussions_reactions;
+DROP TABLE github_discussions;
+DROP TABLE github_discussions_categories;
+
+ALTER TABLE github_metadata DROP COLUMN discussions_updated_at;
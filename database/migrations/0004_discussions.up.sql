@@ -0,0 +1,75 @@
+ALTER TABLE github_metadata ADD COLUMN discussions_updated_at TIMESTAMP NOT NULL DEFAULT '0001-01-01 00:00:00';
+
+--
+-- discussions
+--
+CREATE TABLE github_discussions_categories (
+  id VARCHAR(255) PRIMARY KEY,
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  name VARCHAR(255) NOT NULL,
+  slug VARCHAR(255) NOT NULL,
+  emoji VARCHAR(255) NOT NULL,
+  description TEXT NOT NULL,
+  is_answerable BOOLEAN,
+  created_at TIMESTAMP,
+  updated_at TIMESTAMP
+);
+
+CREATE TABLE github_discussions (
+  id VARCHAR(255) PRIMARY KEY,
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  number BIGINT,
+  category VARCHAR(255) NOT NULL, -- github_discussions_categories_id
+  title TEXT NOT NULL,
+  body TEXT NOT NULL,
+  author VARCHAR(255) NOT NULL, -- github_users_login
+  author_association VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP,
+  published_at TIMESTAMP,
+  updated_at TIMESTAMP,
+  last_edited_at TIMESTAMP,
+  closed_at TIMESTAMP,
+  closed BOOLEAN,
+  locked BOOLEAN,
+  upvote_count BIGINT,
+  is_answered BOOLEAN,
+  answer VARCHAR(255) NOT NULL, -- github_discussions_comments_id
+  answer_chosen_at TIMESTAMP,
+  answer_chosen_by VARCHAR(255) NOT NULL, -- github_users_login
+  labels VARCHAR(255)[] NOT NULL
+);
+
+CREATE TABLE github_discussions_reactions (
+  discussion VARCHAR(255), -- github_discussions_id
+  reaction VARCHAR(255),
+  count BIGINT,
+  PRIMARY KEY (discussion, reaction)
+);
+
+-- Comments and their replies, a reply refers to the comment it replies to.
+CREATE TABLE github_discussions_comments (
+  id VARCHAR(255) PRIMARY KEY,
+  discussion VARCHAR(255) NOT NULL, -- github_discussions_id
+  reply_to VARCHAR(255) NOT NULL, -- github_discussions_comments_id
+  author VARCHAR(255) NOT NULL, -- github_users_login
+  author_association VARCHAR(255) NOT NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMP,
+  published_at TIMESTAMP,
+  updated_at TIMESTAMP,
+  last_edited_at TIMESTAMP,
+  is_answer BOOLEAN,
+  upvote_count BIGINT
+);
+
+CREATE TABLE github_discussions_comments_reactions (
+  comment VARCHAR(255), -- github_discussions_comments_id
+  reaction VARCHAR(255),
+  discussion VARCHAR(255) NOT NULL, -- github_discussions_id
+  count BIGINT,
+  PRIMARY KEY (discussion, comment, reaction)
+);
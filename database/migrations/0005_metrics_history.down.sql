@@ -0,0 +1,5 @@
+DROP TABLE github_metrics_baselines;
+DROP TABLE github_metrics_events;
+DROP TABLE github_metrics_watchers;
+DROP TABLE github_metrics_forks;
+DROP TABLE github_metrics_stargazers;
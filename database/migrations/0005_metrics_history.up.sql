@@ -0,0 +1,54 @@
+--
+-- The current stargazers, forks and watchers of a repository, and the history
+-- of changes to them.
+--
+CREATE TABLE github_metrics_stargazers (
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  login VARCHAR(255) NOT NULL, -- github_users_login
+  starred_at TIMESTAMP,
+  PRIMARY KEY (host, owner, repository, login)
+);
+
+CREATE TABLE github_metrics_forks (
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  fork VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP,
+  PRIMARY KEY (host, owner, repository, fork)
+);
+
+CREATE TABLE github_metrics_watchers (
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  login VARCHAR(255) NOT NULL, -- github_users_login
+  first_seen_at TIMESTAMP,
+  PRIMARY KEY (host, owner, repository, login)
+);
+
+-- star, unstar, fork, unfork, watch and unwatch events, the actor is the login
+-- or the fork.
+CREATE TABLE github_metrics_events (
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  event VARCHAR(255) NOT NULL,
+  actor VARCHAR(255) NOT NULL,
+  occurred_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (host, owner, repository, event, actor, occurred_at)
+);
+
+-- When the stargazers, forks and watchers of a repository were first
+-- collected. The members found by the first collection are the baseline and
+-- are not recorded as events at the time of the collection.
+CREATE TABLE github_metrics_baselines (
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  kind VARCHAR(255) NOT NULL,
+  collected_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (host, owner, repository, kind)
+);
@@ -0,0 +1,9 @@
+DELETE FROM github_metrics_views WHERE period <> 'rollup';
+ALTER TABLE github_metrics_views DROP CONSTRAINT github_metrics_views_pkey;
+ALTER TABLE github_metrics_views DROP COLUMN period;
+ALTER TABLE github_metrics_views ADD PRIMARY KEY (host, owner, repository, date);
+
+DELETE FROM github_metrics_clones WHERE period <> 'rollup';
+ALTER TABLE github_metrics_clones DROP CONSTRAINT github_metrics_clones_pkey;
+ALTER TABLE github_metrics_clones DROP COLUMN period;
+ALTER TABLE github_metrics_clones ADD PRIMARY KEY (host, owner, repository, date);
@@ -0,0 +1,14 @@
+--
+-- Store the clones and views per day or week of the traffic instead of the
+-- total of the last 14 days at the time of the run. The existing rows hold
+-- such totals, and are kept with the period rollup.
+--
+ALTER TABLE github_metrics_clones ADD COLUMN period VARCHAR(255) NOT NULL DEFAULT 'rollup';
+ALTER TABLE github_metrics_clones ALTER COLUMN period DROP DEFAULT;
+ALTER TABLE github_metrics_clones DROP CONSTRAINT github_metrics_clones_pkey;
+ALTER TABLE github_metrics_clones ADD PRIMARY KEY (host, owner, repository, period, date);
+
+ALTER TABLE github_metrics_views ADD COLUMN period VARCHAR(255) NOT NULL DEFAULT 'rollup';
+ALTER TABLE github_metrics_views ALTER COLUMN period DROP DEFAULT;
+ALTER TABLE github_metrics_views DROP CONSTRAINT github_metrics_views_pkey;
+ALTER TABLE github_metrics_views ADD PRIMARY KEY (host, owner, repository, period, date);
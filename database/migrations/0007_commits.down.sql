@@ -0,0 +1,5 @@
+DROP TABLE github_commits_coauthors;
+DROP TABLE github_commits_branches;
+DROP TABLE github_commits;
+
+ALTER TABLE github_metadata DROP COLUMN commits_heads;
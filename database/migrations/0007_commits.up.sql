@@ -0,0 +1,53 @@
+-- The head commit of every branch at the last run, e.g. {"main": "4b82..."}.
+ALTER TABLE github_metadata ADD COLUMN commits_heads JSONB NOT NULL DEFAULT '{}';
+
+--
+-- commits
+--
+CREATE TABLE github_commits (
+  sha VARCHAR(255) NOT NULL,
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  url VARCHAR(255) NOT NULL,
+  message_headline TEXT NOT NULL,
+  message TEXT NOT NULL,
+  author VARCHAR(255) NOT NULL, -- github_users_login
+  author_name VARCHAR(255) NOT NULL,
+  author_email VARCHAR(255) NOT NULL,
+  authored_at TIMESTAMP,
+  committer VARCHAR(255) NOT NULL, -- github_users_login
+  committer_name VARCHAR(255) NOT NULL,
+  committer_email VARCHAR(255) NOT NULL,
+  committed_at TIMESTAMP,
+  additions INT,
+  deletions INT,
+  changed_files INT,
+  parents INT,
+  signed BOOLEAN,
+  signature_valid BOOLEAN,
+  signature_state VARCHAR(255) NOT NULL,
+  signer VARCHAR(255) NOT NULL, -- github_users_login
+  PRIMARY KEY (host, owner, repository, sha)
+);
+
+-- The branches a commit was found on.
+CREATE TABLE github_commits_branches (
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  branch VARCHAR(255) NOT NULL,
+  sha VARCHAR(255) NOT NULL, -- github_commits_sha
+  PRIMARY KEY (host, owner, repository, branch, sha)
+);
+
+-- The co-authors of the Co-authored-by trailers of a commit message.
+CREATE TABLE github_commits_coauthors (
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  sha VARCHAR(255) NOT NULL, -- github_commits_sha
+  name VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  PRIMARY KEY (host, owner, repository, sha, email)
+);
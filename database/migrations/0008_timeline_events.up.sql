@@ -0,0 +1,23 @@
+--
+-- timeline events of issues and pullrequests
+--
+CREATE TABLE github_timeline_events (
+  id VARCHAR(255) PRIMARY KEY,
+  item VARCHAR(255) NOT NULL, -- github_issues_id or github_pullrequests_id
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  number INT NOT NULL,
+  event VARCHAR(255) NOT NULL,
+  actor VARCHAR(255) NOT NULL, -- github_users_login
+  created_at TIMESTAMP,
+  label VARCHAR(255) NOT NULL,
+  assignee VARCHAR(255) NOT NULL, -- github_users_login
+  milestone VARCHAR(255) NOT NULL,
+  source TEXT NOT NULL,
+  previous_title TEXT NOT NULL,
+  title TEXT NOT NULL,
+  reviewer VARCHAR(255) NOT NULL -- github_users_login or organization/team
+);
+
+CREATE INDEX github_timeline_events_item ON github_timeline_events (item);
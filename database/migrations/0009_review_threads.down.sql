@@ -0,0 +1,2 @@
+DROP TABLE github_pullrequests_review_comments;
+DROP TABLE github_pullrequests_review_threads;
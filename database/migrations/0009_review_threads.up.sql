@@ -0,0 +1,41 @@
+--
+-- review threads of pullrequests and their inline review comments
+--
+CREATE TABLE github_pullrequests_review_threads (
+  id VARCHAR(255) PRIMARY KEY,
+  pullrequest VARCHAR(255) NOT NULL, -- github_pullrequests_id
+  path TEXT NOT NULL,
+  line INT,
+  original_line INT,
+  start_line INT,
+  original_start_line INT,
+  diff_side VARCHAR(255) NOT NULL,
+  is_resolved BOOLEAN,
+  is_outdated BOOLEAN,
+  is_collapsed BOOLEAN,
+  resolved_by VARCHAR(255) NOT NULL -- github_users_login
+);
+
+CREATE INDEX github_pullrequests_review_threads_pullrequest ON github_pullrequests_review_threads (pullrequest);
+
+CREATE TABLE github_pullrequests_review_comments (
+  id VARCHAR(255) PRIMARY KEY,
+  pullrequest VARCHAR(255) NOT NULL, -- github_pullrequests_id
+  thread VARCHAR(255) NOT NULL, -- github_pullrequests_review_threads_id
+  review VARCHAR(255) NOT NULL,
+  reply_to VARCHAR(255) NOT NULL, -- github_pullrequests_review_comments_id
+  author VARCHAR(255) NOT NULL, -- github_users_login
+  author_association VARCHAR(255) NOT NULL,
+  body TEXT NOT NULL,
+  diff_hunk TEXT NOT NULL,
+  path TEXT NOT NULL,
+  line INT,
+  original_line INT,
+  outdated BOOLEAN,
+  created_at TIMESTAMP,
+  published_at TIMESTAMP,
+  updated_at TIMESTAMP,
+  last_edited_at TIMESTAMP
+);
+
+CREATE INDEX github_pullrequests_review_comments_thread ON github_pullrequests_review_comments (thread);
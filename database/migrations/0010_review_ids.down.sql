@@ -0,0 +1,11 @@
+-- Keep the latest review of every author.
+DELETE FROM github_pullrequests_reviews r
+USING github_pullrequests_reviews newer
+WHERE r.pullrequest = newer.pullrequest
+  AND r.author = newer.author
+  AND (COALESCE(r.submitted_at, '0001-01-01'), r.id) < (COALESCE(newer.submitted_at, '0001-01-01'), newer.id);
+
+DROP INDEX github_pullrequests_reviews_pullrequest;
+ALTER TABLE github_pullrequests_reviews DROP CONSTRAINT github_pullrequests_reviews_pkey;
+ALTER TABLE github_pullrequests_reviews DROP COLUMN id;
+ALTER TABLE github_pullrequests_reviews ADD PRIMARY KEY (pullrequest, author);
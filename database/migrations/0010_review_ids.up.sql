@@ -0,0 +1,12 @@
+--
+-- Store every review of a pullrequest by its id instead of only the latest
+-- review of every author. The existing reviews have no id, they are given a
+-- legacy id and replaced when their pullrequest is collected again.
+--
+ALTER TABLE github_pullrequests_reviews ADD COLUMN id VARCHAR(255);
+UPDATE github_pullrequests_reviews SET id = 'legacy:' || pullrequest || ':' || author;
+ALTER TABLE github_pullrequests_reviews ALTER COLUMN id SET NOT NULL;
+ALTER TABLE github_pullrequests_reviews DROP CONSTRAINT github_pullrequests_reviews_pkey;
+ALTER TABLE github_pullrequests_reviews ADD PRIMARY KEY (id);
+
+CREATE INDEX github_pullrequests_reviews_pullrequest ON github_pullrequests_reviews (pullrequest);
@@ -0,0 +1,4 @@
+ALTER TABLE github_releases DROP COLUMN truncated;
+ALTER TABLE github_discussions DROP COLUMN truncated;
+ALTER TABLE github_pullrequests DROP COLUMN truncated;
+ALTER TABLE github_issues DROP COLUMN truncated;
@@ -0,0 +1,6 @@
+-- Whether GitHub returned fewer nested nodes, such as labels or files, than it
+-- counted.
+ALTER TABLE github_issues ADD COLUMN truncated BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_pullrequests ADD COLUMN truncated BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_discussions ADD COLUMN truncated BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_releases ADD COLUMN truncated BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+ALTER TABLE github_metadata DROP COLUMN releases_updated_at;
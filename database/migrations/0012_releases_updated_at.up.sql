@@ -0,0 +1 @@
+ALTER TABLE github_metadata ADD COLUMN releases_updated_at TIMESTAMP NOT NULL DEFAULT '0001-01-01 00:00:00';
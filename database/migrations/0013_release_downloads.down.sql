@@ -0,0 +1,8 @@
+DROP VIEW github_releases_versions_downloads_daily;
+DROP VIEW github_releases_platforms_downloads_daily;
+DROP VIEW github_releases_downloads_daily;
+DROP VIEW github_releases_assets_downloads_daily;
+DROP TABLE github_releases_assets_downloads;
+ALTER TABLE github_releases_assets DROP COLUMN version;
+ALTER TABLE github_releases_assets DROP COLUMN arch;
+ALTER TABLE github_releases_assets DROP COLUMN os;
@@ -0,0 +1,74 @@
+--
+-- The platform and version of release assets, parsed from their names, and a
+-- daily snapshot of their cumulative download counts.
+--
+ALTER TABLE github_releases_assets ADD COLUMN os VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_releases_assets ADD COLUMN arch VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_releases_assets ADD COLUMN version VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Collect every release again on the next run, so that the stored assets get
+-- their platform and version.
+UPDATE github_metadata SET releases_updated_at = '0001-01-01 00:00:00';
+
+CREATE TABLE github_releases_assets_downloads (
+  asset VARCHAR(255) NOT NULL, -- github_releases_assets_id
+  release VARCHAR(255) NOT NULL, -- github_releases_id
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  date DATE NOT NULL,
+  downloads BIGINT NOT NULL,
+  collected_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (asset, date)
+);
+
+-- The downloads of an asset per day. The downloads of a day that has no
+-- snapshot of its own are estimated by spreading the downloads since the
+-- previous snapshot evenly, so that the deltas of the days still add up to the
+-- downloads between both snapshots. days is the number of days they were
+-- spread over. The delta of the first snapshot of an asset is NULL, as the
+-- downloads before it are not known per day.
+CREATE VIEW github_releases_assets_downloads_daily AS
+WITH snapshots AS (
+  SELECT
+    d.*,
+    d.downloads - LAG(d.downloads) OVER w AS delta,
+    d.date - LAG(d.date) OVER w AS days
+  FROM github_releases_assets_downloads d
+  WINDOW w AS (PARTITION BY d.asset ORDER BY d.date)
+)
+SELECT
+  s.host,
+  s.owner,
+  s.repository,
+  s.release,
+  r.tag,
+  s.asset,
+  a.name,
+  a.os,
+  a.arch,
+  a.version,
+  (s.date - COALESCE(s.days, 1) + i.day)::DATE AS date,
+  s.downloads - COALESCE(s.delta - s.delta * i.day / s.days, 0) AS downloads,
+  s.delta * i.day / s.days - s.delta * (i.day - 1) / s.days AS delta,
+  s.days,
+  i.day < COALESCE(s.days, 1) AS estimated
+FROM snapshots s
+CROSS JOIN LATERAL generate_series(1, COALESCE(s.days, 1)) AS i(day)
+JOIN github_releases_assets a ON a.id = s.asset
+JOIN github_releases r ON r.id = s.release;
+
+CREATE VIEW github_releases_downloads_daily AS
+SELECT host, owner, repository, release, tag, date, SUM(downloads) AS downloads, SUM(delta) AS delta
+FROM github_releases_assets_downloads_daily
+GROUP BY host, owner, repository, release, tag, date;
+
+CREATE VIEW github_releases_platforms_downloads_daily AS
+SELECT host, owner, repository, os, arch, date, SUM(downloads) AS downloads, SUM(delta) AS delta
+FROM github_releases_assets_downloads_daily
+GROUP BY host, owner, repository, os, arch, date;
+
+CREATE VIEW github_releases_versions_downloads_daily AS
+SELECT host, owner, repository, version, date, SUM(downloads) AS downloads, SUM(delta) AS delta
+FROM github_releases_assets_downloads_daily
+GROUP BY host, owner, repository, version, date;
@@ -0,0 +1,23 @@
+--
+-- The profiles of the users, bots and organizations seen in the collected
+-- data. The type is empty for logins that no longer exist.
+--
+CREATE TABLE github_users (
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  login VARCHAR(255) NOT NULL,
+  id VARCHAR(255) NOT NULL,
+  type VARCHAR(255) NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  company VARCHAR(255) NOT NULL,
+  location VARCHAR(255) NOT NULL,
+  bio TEXT NOT NULL,
+  blog TEXT NOT NULL,
+  followers BIGINT,
+  following BIGINT,
+  public_repos BIGINT,
+  created_at TIMESTAMP,
+  updated_at TIMESTAMP,
+  collected_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (host, login)
+);
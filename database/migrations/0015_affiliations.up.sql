@@ -0,0 +1,16 @@
+--
+-- The organizations logins are affiliated with, and what they are to the
+-- project, e.g. employee, partner or community. A login has a row for every
+-- period of an affiliation, a NULL date leaves the period open.
+--
+CREATE TABLE github_affiliations (
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  login VARCHAR(255) NOT NULL, -- github_users_login
+  organization VARCHAR(255) NOT NULL,
+  affiliation VARCHAR(255) NOT NULL,
+  source VARCHAR(255) NOT NULL,
+  valid_from DATE,
+  valid_until DATE
+);
+
+CREATE INDEX github_affiliations_login ON github_affiliations (host, login);
@@ -0,0 +1,17 @@
+ALTER TABLE github_commits DROP COLUMN is_bot;
+ALTER TABLE github_discussions_comments DROP COLUMN is_bot;
+ALTER TABLE github_discussions_comments DROP COLUMN author_type;
+ALTER TABLE github_discussions DROP COLUMN is_bot;
+ALTER TABLE github_discussions DROP COLUMN author_type;
+ALTER TABLE github_pullrequests_review_comments DROP COLUMN is_bot;
+ALTER TABLE github_pullrequests_review_comments DROP COLUMN author_type;
+ALTER TABLE github_pullrequests_reviews DROP COLUMN is_bot;
+ALTER TABLE github_pullrequests_reviews DROP COLUMN author_type;
+ALTER TABLE github_pullrequests_comments DROP COLUMN is_bot;
+ALTER TABLE github_pullrequests_comments DROP COLUMN author_type;
+ALTER TABLE github_pullrequests DROP COLUMN is_bot;
+ALTER TABLE github_pullrequests DROP COLUMN author_type;
+ALTER TABLE github_issues_comments DROP COLUMN is_bot;
+ALTER TABLE github_issues_comments DROP COLUMN author_type;
+ALTER TABLE github_issues DROP COLUMN is_bot;
+ALTER TABLE github_issues DROP COLUMN author_type;
@@ -0,0 +1,21 @@
+--
+-- The type of the author of a row, User, Bot, Organization or Mannequin, and
+-- whether the author is a bot by its type or by the bot patterns.
+--
+ALTER TABLE github_issues ADD COLUMN author_type VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_issues ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_issues_comments ADD COLUMN author_type VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_issues_comments ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_pullrequests ADD COLUMN author_type VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_pullrequests ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_pullrequests_comments ADD COLUMN author_type VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_pullrequests_comments ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_pullrequests_reviews ADD COLUMN author_type VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_pullrequests_reviews ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_pullrequests_review_comments ADD COLUMN author_type VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_pullrequests_review_comments ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_discussions ADD COLUMN author_type VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_discussions ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_discussions_comments ADD COLUMN author_type VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE github_discussions_comments ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE github_commits ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+DROP TABLE github_items_assignees;
+DROP TABLE github_items_labels;
+ALTER TABLE github_pullrequests DROP COLUMN milestone;
+ALTER TABLE github_issues DROP COLUMN milestone;
+DROP TABLE github_milestones;
+DROP TABLE github_labels;
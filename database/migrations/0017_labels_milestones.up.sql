@@ -0,0 +1,69 @@
+--
+-- The label definitions and milestones of repositories, and the labels,
+-- milestone and assignees of issues and pullrequests.
+--
+CREATE TABLE github_labels (
+  id VARCHAR(255) PRIMARY KEY,
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  name VARCHAR(255) NOT NULL,
+  color VARCHAR(255) NOT NULL,
+  description TEXT NOT NULL,
+  is_default BOOLEAN,
+  created_at TIMESTAMP,
+  updated_at TIMESTAMP
+);
+
+CREATE INDEX github_labels_repository ON github_labels (host, owner, repository);
+
+CREATE TABLE github_milestones (
+  id VARCHAR(255) PRIMARY KEY,
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  number INT NOT NULL,
+  title TEXT NOT NULL,
+  description TEXT NOT NULL,
+  url TEXT NOT NULL,
+  state VARCHAR(255) NOT NULL,
+  due_on TIMESTAMP,
+  created_at TIMESTAMP,
+  updated_at TIMESTAMP,
+  closed_at TIMESTAMP,
+  progress_percentage DOUBLE PRECISION,
+  open_issues BIGINT,
+  closed_issues BIGINT,
+  open_pullrequests BIGINT,
+  closed_pullrequests BIGINT
+);
+
+CREATE INDEX github_milestones_repository ON github_milestones (host, owner, repository);
+
+ALTER TABLE github_issues ADD COLUMN milestone VARCHAR(255) NOT NULL DEFAULT ''; -- github_milestones_id
+ALTER TABLE github_pullrequests ADD COLUMN milestone VARCHAR(255) NOT NULL DEFAULT ''; -- github_milestones_id
+
+CREATE TABLE github_items_labels (
+  item VARCHAR(255) NOT NULL, -- github_issues_id or github_pullrequests_id
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  number INT NOT NULL,
+  label VARCHAR(255) NOT NULL, -- github_labels_id
+  name VARCHAR(255) NOT NULL,
+  PRIMARY KEY (item, label)
+);
+
+CREATE INDEX github_items_labels_label ON github_items_labels (label);
+
+CREATE TABLE github_items_assignees (
+  item VARCHAR(255) NOT NULL, -- github_issues_id or github_pullrequests_id
+  host VARCHAR(255) NOT NULL, -- github_metadata_host
+  owner VARCHAR(255) NOT NULL, -- github_metadata_owner
+  repository VARCHAR(255) NOT NULL, -- github_metadata_repository
+  number INT NOT NULL,
+  login VARCHAR(255) NOT NULL, -- github_users_login
+  PRIMARY KEY (item, login)
+);
+
+CREATE INDEX github_items_assignees_login ON github_items_assignees (host, login);
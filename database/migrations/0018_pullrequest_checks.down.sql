@@ -0,0 +1,4 @@
+DROP TABLE github_pullrequests_checks;
+DROP TABLE github_pullrequests_commits;
+ALTER TABLE github_pullrequests DROP COLUMN checks_state;
+ALTER TABLE github_pullrequests DROP COLUMN head_sha;
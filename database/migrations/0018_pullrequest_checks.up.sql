@@ -0,0 +1,37 @@
+--
+-- The commits of pullrequests, and the CI status and checks of their head
+-- commits.
+--
+ALTER TABLE github_pullrequests ADD COLUMN head_sha VARCHAR(255) NOT NULL DEFAULT ''; -- github_commits_sha
+ALTER TABLE github_pullrequests ADD COLUMN checks_state VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE TABLE github_pullrequests_commits (
+  pullrequest VARCHAR(255) NOT NULL, -- github_pullrequests_id
+  sha VARCHAR(255) NOT NULL, -- github_commits_sha
+  message_headline TEXT NOT NULL,
+  author VARCHAR(255) NOT NULL, -- github_users_login
+  author_name VARCHAR(255) NOT NULL,
+  author_email VARCHAR(255) NOT NULL,
+  authored_at TIMESTAMP,
+  committed_at TIMESTAMP,
+  PRIMARY KEY (pullrequest, sha)
+);
+
+CREATE TABLE github_pullrequests_checks (
+  id VARCHAR(255) PRIMARY KEY,
+  pullrequest VARCHAR(255) NOT NULL, -- github_pullrequests_id
+  sha VARCHAR(255) NOT NULL, -- github_commits_sha
+  type VARCHAR(255) NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL,
+  app VARCHAR(255) NOT NULL,
+  workflow TEXT NOT NULL,
+  status VARCHAR(255) NOT NULL,
+  conclusion VARCHAR(255) NOT NULL,
+  url TEXT NOT NULL,
+  started_at TIMESTAMP,
+  completed_at TIMESTAMP,
+  duration BIGINT
+);
+
+CREATE INDEX github_pullrequests_checks_pullrequest ON github_pullrequests_checks (pullrequest, sha);
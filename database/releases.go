@@ -0,0 +1,310 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ReleaseAssetDownloads struct {
+	Asset       string    `json:"asset" db:"asset"`
+	Release     string    `json:"release" db:"release"`
+	Host        string    `json:"host" db:"host"`
+	Owner       string    `json:"owner" db:"owner"`
+	Repository  string    `json:"repository" db:"repository"`
+	Date        time.Time `json:"date" db:"date"`
+	Downloads   int       `json:"downloads" db:"downloads"`
+	CollectedAt time.Time `json:"collected_at" db:"collected_at"`
+}
+
+// Releases
+const insertRelease = `INSERT INTO github_releases (
+		id,
+		host,
+		owner,
+		repository,
+		name,
+		description,
+		url,
+		created_at,
+		is_prerelease,
+		tag,
+		truncated
+	)
+	VALUES (
+		:id,
+		:host,
+		:owner,
+		:repository,
+		:name,
+		:description,
+		:url,
+		:created_at,
+		:is_prerelease,
+		:tag,
+		:truncated
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		name = EXCLUDED.name,
+		description = EXCLUDED.description,
+		url = EXCLUDED.url,
+		created_at = EXCLUDED.created_at,
+		is_prerelease = EXCLUDED.is_prerelease,
+		tag = EXCLUDED.tag,
+		truncated = EXCLUDED.truncated
+	RETURNING *`
+
+const insertReleaseAsset = `INSERT INTO github_releases_assets (
+		id,
+		release,
+		host,
+		owner,
+		repository,
+		name,
+		downloads,
+		size,
+		os,
+		arch,
+		version
+	)
+	VALUES (
+		:id,
+		:release,
+		:host,
+		:owner,
+		:repository,
+		:name,
+		:downloads,
+		:size,
+		:os,
+		:arch,
+		:version
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		name = EXCLUDED.name,
+		downloads = EXCLUDED.downloads,
+		size = EXCLUDED.size,
+		os = EXCLUDED.os,
+		arch = EXCLUDED.arch,
+		version = EXCLUDED.version
+	RETURNING *`
+
+// The download count of an asset at the last run of a day.
+const insertReleaseAssetDownloads = `INSERT INTO github_releases_assets_downloads (
+		asset,
+		release,
+		host,
+		owner,
+		repository,
+		date,
+		downloads,
+		collected_at
+	)
+	VALUES (
+		:asset,
+		:release,
+		:host,
+		:owner,
+		:repository,
+		:date,
+		:downloads,
+		:collected_at
+	)
+	ON CONFLICT (asset, date) DO UPDATE
+	SET
+		downloads = EXCLUDED.downloads,
+		collected_at = EXCLUDED.collected_at`
+
+// Only update existing assets, an asset of a release that has not been
+// collected yet is left to the next run of releases.
+// The rows of the values are added per batch by updateAssetDownloads.
+const updateReleaseAssetDownloads = `UPDATE github_releases_assets a
+	SET downloads = v.downloads
+	FROM (VALUES %s) AS v (id, downloads)
+	WHERE a.id = v.id
+	RETURNING a.id`
+
+// SaveReleases writes a page of releases with their assets in a single
+// transaction.
+func (db *DatabaseImpl) SaveReleases(input []Release) error {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// A batch may not touch the same row twice.
+	seen := map[string]bool{}
+	releases := []Release{}
+	assets := []ReleaseAsset{}
+	for _, r := range input {
+		if seen[r.ID] {
+			continue
+		}
+		seen[r.ID] = true
+		releases = append(releases, r)
+
+		for _, a := range r.Assets {
+			if seen[a.ID] {
+				continue
+			}
+			seen[a.ID] = true
+
+			a.Release = r.ID
+			assets = append(assets, a)
+		}
+	}
+
+	err = execBatch(tx, insertRelease, releases)
+	if err != nil {
+		return fmt.Errorf("could not add releases: %v", err)
+	}
+
+	err = execBatch(tx, insertReleaseAsset, assets)
+	if err != nil {
+		return fmt.Errorf("could not add release assets: %v", err)
+	}
+
+	err = saveReleaseAssetDownloads(tx, assets)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveReleasesUpdatedAt advances the creation time of the newest collected
+// release in the metadata, once every newer release has been written.
+func (db *DatabaseImpl) SaveReleasesUpdatedAt(metadata Metadata, updatedAt time.Time) (Metadata, error) {
+	if !updatedAt.After(metadata.ReleasesUpdatedAt) {
+		return metadata, nil
+	}
+
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return metadata, err
+	}
+	defer tx.Rollback()
+
+	updated := metadata
+	updated.ReleasesUpdatedAt = updatedAt
+
+	err = updateMetadata(tx, "releases_updated_at", &updated)
+	if err != nil {
+		return metadata, fmt.Errorf("could not update metadata: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return metadata, err
+	}
+
+	return updated, nil
+}
+
+// UpdateReleaseDownloads updates the download counts of assets that have
+// already been stored, in a single transaction.
+func (db *DatabaseImpl) UpdateReleaseDownloads(input []ReleaseAsset) error {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// A batch may not touch the same row twice.
+	seen := map[string]bool{}
+	unique := []ReleaseAsset{}
+	for _, a := range input {
+		if seen[a.ID] {
+			continue
+		}
+		seen[a.ID] = true
+		unique = append(unique, a)
+	}
+
+	updated, err := updateAssetDownloads(tx, unique)
+	if err != nil {
+		return fmt.Errorf("could not update downloads of assets: %v", err)
+	}
+
+	// Only stored assets get a snapshot, the others have no name or release
+	// to break the downloads down by yet.
+	assets := []ReleaseAsset{}
+	for _, a := range unique {
+		if updated[a.ID] {
+			assets = append(assets, a)
+		}
+	}
+
+	err = saveReleaseAssetDownloads(tx, assets)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update the download counts of stored assets in batches, returning the ids of
+// the assets that were stored.
+func updateAssetDownloads(tx *sqlx.Tx, assets []ReleaseAsset) (map[string]bool, error) {
+	updated := map[string]bool{}
+
+	for i := 0; i < len(assets); i += batchSize {
+		j := i + batchSize
+		if j > len(assets) {
+			j = len(assets)
+		}
+
+		rows := []string{}
+		args := []interface{}{}
+		for _, a := range assets[i:j] {
+			rows = append(rows, fmt.Sprintf("($%d, $%d::BIGINT)", len(args)+1, len(args)+2))
+			args = append(args, a.ID, a.Downloads)
+		}
+
+		ids := []string{}
+		err := tx.Select(&ids, fmt.Sprintf(updateReleaseAssetDownloads, strings.Join(rows, ", ")), args...)
+		if err != nil {
+			return updated, err
+		}
+
+		for _, id := range ids {
+			updated[id] = true
+		}
+	}
+
+	return updated, nil
+}
+
+// Write a snapshot of the download counts of assets, keyed by the day they
+// were collected on.
+func saveReleaseAssetDownloads(tx *sqlx.Tx, assets []ReleaseAsset) error {
+	snapshots := []ReleaseAssetDownloads{}
+	for _, a := range assets {
+		if a.CollectedAt.IsZero() {
+			continue
+		}
+
+		snapshots = append(snapshots, ReleaseAssetDownloads{
+			Asset:       a.ID,
+			Release:     a.Release,
+			Host:        a.Host,
+			Owner:       a.Owner,
+			Repository:  a.Repository,
+			Date:        a.CollectedAt.Truncate(24 * time.Hour),
+			Downloads:   a.Downloads,
+			CollectedAt: a.CollectedAt,
+		})
+	}
+
+	err := execBatch(tx, insertReleaseAssetDownloads, snapshots)
+	if err != nil {
+		return fmt.Errorf("could not add release asset downloads: %v", err)
+	}
+
+	return nil
+}
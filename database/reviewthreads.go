@@ -0,0 +1,192 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PullrequestReviewThread struct {
+	ID                string                     `json:"id" db:"id"`
+	Pullrequest       string                     `json:"-" db:"pullrequest"`
+	Path              string                     `json:"path" db:"path"`
+	Line              int                        `json:"line" db:"line"`
+	OriginalLine      int                        `json:"original_line" db:"original_line"`
+	StartLine         int                        `json:"start_line" db:"start_line"`
+	OriginalStartLine int                        `json:"original_start_line" db:"original_start_line"`
+	DiffSide          string                     `json:"diff_side" db:"diff_side"`
+	IsResolved        bool                       `json:"is_resolved" db:"is_resolved"`
+	IsOutdated        bool                       `json:"is_outdated" db:"is_outdated"`
+	IsCollapsed       bool                       `json:"is_collapsed" db:"is_collapsed"`
+	ResolvedBy        string                     `json:"resolved_by" db:"resolved_by"`
+	Comments          []PullrequestReviewComment `json:"comments" db:"-"`
+}
+
+type PullrequestReviewComment struct {
+	ID                string    `json:"id" db:"id"`
+	Pullrequest       string    `json:"-" db:"pullrequest"`
+	Thread            string    `json:"-" db:"thread"`
+	Review            string    `json:"review" db:"review"`
+	ReplyTo           string    `json:"reply_to" db:"reply_to"`
+	Author            string    `json:"author" db:"author"`
+	AuthorAssociation string    `json:"author_association" db:"author_association"`
+	AuthorType        string    `json:"author_type" db:"author_type"`
+	IsBot             bool      `json:"is_bot" db:"is_bot"`
+	Body              string    `json:"body" db:"body"`
+	DiffHunk          string    `json:"diff_hunk" db:"diff_hunk"`
+	Path              string    `json:"path" db:"path"`
+	Line              int       `json:"line" db:"line"`
+	OriginalLine      int       `json:"original_line" db:"original_line"`
+	Outdated          bool      `json:"outdated" db:"outdated"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	PublishedAt       time.Time `json:"published_at" db:"published_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	LastEditedAt      time.Time `json:"last_edited_at" db:"last_edited_at"`
+}
+
+// Review threads
+const insertPullrequestReviewThread = `INSERT INTO github_pullrequests_review_threads (
+		id,
+		pullrequest,
+		path,
+		line,
+		original_line,
+		start_line,
+		original_start_line,
+		diff_side,
+		is_resolved,
+		is_outdated,
+		is_collapsed,
+		resolved_by
+	)
+	VALUES (
+		:id,
+		:pullrequest,
+		:path,
+		:line,
+		:original_line,
+		:start_line,
+		:original_start_line,
+		:diff_side,
+		:is_resolved,
+		:is_outdated,
+		:is_collapsed,
+		:resolved_by
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		pullrequest = EXCLUDED.pullrequest,
+		path = EXCLUDED.path,
+		line = EXCLUDED.line,
+		original_line = EXCLUDED.original_line,
+		start_line = EXCLUDED.start_line,
+		original_start_line = EXCLUDED.original_start_line,
+		diff_side = EXCLUDED.diff_side,
+		is_resolved = EXCLUDED.is_resolved,
+		is_outdated = EXCLUDED.is_outdated,
+		is_collapsed = EXCLUDED.is_collapsed,
+		resolved_by = EXCLUDED.resolved_by`
+
+const insertPullrequestReviewComment = `INSERT INTO github_pullrequests_review_comments (
+		id,
+		pullrequest,
+		thread,
+		review,
+		reply_to,
+		author,
+		author_association,
+		author_type,
+		is_bot,
+		body,
+		diff_hunk,
+		path,
+		line,
+		original_line,
+		outdated,
+		created_at,
+		published_at,
+		updated_at,
+		last_edited_at
+	)
+	VALUES (
+		:id,
+		:pullrequest,
+		:thread,
+		:review,
+		:reply_to,
+		:author,
+		:author_association,
+		:author_type,
+		:is_bot,
+		:body,
+		:diff_hunk,
+		:path,
+		:line,
+		:original_line,
+		:outdated,
+		:created_at,
+		:published_at,
+		:updated_at,
+		:last_edited_at
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		pullrequest = EXCLUDED.pullrequest,
+		thread = EXCLUDED.thread,
+		review = EXCLUDED.review,
+		reply_to = EXCLUDED.reply_to,
+		author = EXCLUDED.author,
+		author_association = EXCLUDED.author_association,
+		author_type = EXCLUDED.author_type,
+		is_bot = EXCLUDED.is_bot,
+		body = EXCLUDED.body,
+		diff_hunk = EXCLUDED.diff_hunk,
+		path = EXCLUDED.path,
+		line = EXCLUDED.line,
+		original_line = EXCLUDED.original_line,
+		outdated = EXCLUDED.outdated,
+		created_at = EXCLUDED.created_at,
+		published_at = EXCLUDED.published_at,
+		updated_at = EXCLUDED.updated_at,
+		last_edited_at = EXCLUDED.last_edited_at`
+
+// Write the review threads of a pullrequest with their comments.
+func saveReviewThreads(tx *sqlx.Tx, pullrequest string, input []PullrequestReviewThread) error {
+	// A batch may not touch the same row twice.
+	seen := map[string]bool{}
+	threads := []PullrequestReviewThread{}
+	comments := []PullrequestReviewComment{}
+	for _, t := range input {
+		if seen[t.ID] {
+			continue
+		}
+		seen[t.ID] = true
+
+		t.Pullrequest = pullrequest
+		threads = append(threads, t)
+
+		for _, c := range t.Comments {
+			if seen[c.ID] {
+				continue
+			}
+			seen[c.ID] = true
+
+			c.Pullrequest = pullrequest
+			c.Thread = t.ID
+			comments = append(comments, c)
+		}
+	}
+
+	err := execBatch(tx, insertPullrequestReviewThread, threads)
+	if err != nil {
+		return fmt.Errorf("could not add review threads: %v", err)
+	}
+
+	err = execBatch(tx, insertPullrequestReviewComment, comments)
+	if err != nil {
+		return fmt.Errorf("could not add review comments: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TimelineEvent is an event of the timeline of an issue or pullrequest, such
+// as a label being added or a review being requested. Which of the detail
+// fields is set depends on the event.
+type TimelineEvent struct {
+	ID            string    `json:"id" db:"id"`
+	Item          string    `json:"-" db:"item"`
+	Host          string    `json:"-" db:"host"`
+	Owner         string    `json:"-" db:"owner"`
+	Repository    string    `json:"-" db:"repository"`
+	Number        int       `json:"-" db:"number"`
+	Event         string    `json:"event" db:"event"`
+	Actor         string    `json:"actor" db:"actor"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	Label         string    `json:"label" db:"label"`
+	Assignee      string    `json:"assignee" db:"assignee"`
+	Milestone     string    `json:"milestone" db:"milestone"`
+	Source        string    `json:"source" db:"source"`
+	PreviousTitle string    `json:"previous_title" db:"previous_title"`
+	Title         string    `json:"title" db:"title"`
+	Reviewer      string    `json:"reviewer" db:"reviewer"`
+}
+
+// Timeline events
+const insertTimelineEvent = `INSERT INTO github_timeline_events (
+		id,
+		item,
+		host,
+		owner,
+		repository,
+		number,
+		event,
+		actor,
+		created_at,
+		label,
+		assignee,
+		milestone,
+		source,
+		previous_title,
+		title,
+		reviewer
+	)
+	VALUES (
+		:id,
+		:item,
+		:host,
+		:owner,
+		:repository,
+		:number,
+		:event,
+		:actor,
+		:created_at,
+		:label,
+		:assignee,
+		:milestone,
+		:source,
+		:previous_title,
+		:title,
+		:reviewer
+	)
+	ON CONFLICT (id) DO UPDATE
+	SET
+		item = EXCLUDED.item,
+		host = EXCLUDED.host,
+		owner = EXCLUDED.owner,
+		repository = EXCLUDED.repository,
+		number = EXCLUDED.number,
+		event = EXCLUDED.event,
+		actor = EXCLUDED.actor,
+		created_at = EXCLUDED.created_at,
+		label = EXCLUDED.label,
+		assignee = EXCLUDED.assignee,
+		milestone = EXCLUDED.milestone,
+		source = EXCLUDED.source,
+		previous_title = EXCLUDED.previous_title,
+		title = EXCLUDED.title,
+		reviewer = EXCLUDED.reviewer`
+
+// Write the timeline events of the issue or pullrequest item.
+func saveTimelineEvents(tx *sqlx.Tx, item string, host string, owner string, repository string, number int, input []TimelineEvent) error {
+	// A batch may not touch the same row twice.
+	seen := map[string]bool{}
+	events := []TimelineEvent{}
+	for _, e := range input {
+		if seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+
+		e.Item = item
+		e.Host = host
+		e.Owner = owner
+		e.Repository = repository
+		e.Number = number
+		events = append(events, e)
+	}
+
+	return execBatch(tx, insertTimelineEvent, events)
+}
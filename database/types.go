@@ -2,6 +2,8 @@ package database
 
 import (
 	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"strings"
 )
 
@@ -29,3 +31,45 @@ func (x *StringArray) Scan(src interface{}) error {
 	*x = transform
 	return nil
 }
+
+// Heads maps branches to the SHA of their head commit, stored as JSONB.
+type Heads map[string]string
+
+// e.g. {"main": "4b82..."} -> {"main":"4b82..."}
+func (x Heads) Value() (driver.Value, error) {
+	if x == nil {
+		return "{}", nil
+	}
+
+	transform, err := json.Marshal(map[string]string(x))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(transform), nil
+}
+
+// e.g. {"main":"4b82..."} -> {"main": "4b82..."}
+func (x *Heads) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case nil:
+		*x = Heads{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Heads", src)
+	}
+
+	heads := Heads{}
+	err := json.Unmarshal(data, &heads)
+	if err != nil {
+		return err
+	}
+
+	*x = heads
+	return nil
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+type User struct {
+	Host        string    `json:"host" db:"host"`
+	Login       string    `json:"login" db:"login"`
+	ID          string    `json:"id" db:"id"`
+	Type        string    `json:"type" db:"type"`
+	Name        string    `json:"name" db:"name"`
+	Email       string    `json:"email" db:"email"`
+	Company     string    `json:"company" db:"company"`
+	Location    string    `json:"location" db:"location"`
+	Bio         string    `json:"bio" db:"bio"`
+	Blog        string    `json:"blog" db:"blog"`
+	Followers   int       `json:"followers" db:"followers"`
+	Following   int       `json:"following" db:"following"`
+	PublicRepos int       `json:"public_repos" db:"public_repos"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	CollectedAt time.Time `json:"collected_at" db:"collected_at"`
+}
+
+// A login seen in the data of a repository, and whether it is known to be a
+// bot.
+type Login struct {
+	Login string `json:"login" db:"login"`
+	Bot   bool   `json:"bot" db:"bot"`
+}
+
+// Users
+const insertUser = `INSERT INTO github_users (
+		host,
+		login,
+		id,
+		type,
+		name,
+		email,
+		company,
+		location,
+		bio,
+		blog,
+		followers,
+		following,
+		public_repos,
+		created_at,
+		updated_at,
+		collected_at
+	)
+	VALUES (
+		:host,
+		:login,
+		:id,
+		:type,
+		:name,
+		:email,
+		:company,
+		:location,
+		:bio,
+		:blog,
+		:followers,
+		:following,
+		:public_repos,
+		:created_at,
+		:updated_at,
+		:collected_at
+	)
+	ON CONFLICT (host, login) DO UPDATE
+	SET
+		id = EXCLUDED.id,
+		type = EXCLUDED.type,
+		name = EXCLUDED.name,
+		email = EXCLUDED.email,
+		company = EXCLUDED.company,
+		location = EXCLUDED.location,
+		bio = EXCLUDED.bio,
+		blog = EXCLUDED.blog,
+		followers = EXCLUDED.followers,
+		following = EXCLUDED.following,
+		public_repos = EXCLUDED.public_repos,
+		created_at = EXCLUDED.created_at,
+		updated_at = EXCLUDED.updated_at,
+		collected_at = EXCLUDED.collected_at`
+
+// Every login seen in the data of a repository: the authors, commenters,
+// reviewers, actors, committers, stargazers, watchers and the owners of forks.
+// Teams requested for review are named organization/team and are left out. A
+// login is a bot when it is the author of a row of the type Bot.
+const selectSeenLogins = `WITH seen AS (
+		SELECT author AS login, author_type AS type FROM github_issues
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT closed_by, '' FROM github_issues
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT c.author, c.author_type FROM github_issues_comments c JOIN github_issues i ON i.id = c.issue
+		WHERE i.host = :host AND i.owner = :owner AND i.repository = :repository
+		UNION SELECT author, author_type FROM github_pullrequests
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT closed_by, '' FROM github_pullrequests
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT merged_by, '' FROM github_pullrequests
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT c.author, c.author_type FROM github_pullrequests_comments c JOIN github_pullrequests p ON p.id = c.pullrequest
+		WHERE p.host = :host AND p.owner = :owner AND p.repository = :repository
+		UNION SELECT r.author, r.author_type FROM github_pullrequests_reviews r JOIN github_pullrequests p ON p.id = r.pullrequest
+		WHERE p.host = :host AND p.owner = :owner AND p.repository = :repository
+		UNION SELECT c.author, c.author_type FROM github_pullrequests_review_comments c JOIN github_pullrequests p ON p.id = c.pullrequest
+		WHERE p.host = :host AND p.owner = :owner AND p.repository = :repository
+		UNION SELECT t.resolved_by, '' FROM github_pullrequests_review_threads t JOIN github_pullrequests p ON p.id = t.pullrequest
+		WHERE p.host = :host AND p.owner = :owner AND p.repository = :repository
+		UNION SELECT author, author_type FROM github_discussions
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT answer_chosen_by, '' FROM github_discussions
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT c.author, c.author_type FROM github_discussions_comments c JOIN github_discussions d ON d.id = c.discussion
+		WHERE d.host = :host AND d.owner = :owner AND d.repository = :repository
+		UNION SELECT actor, '' FROM github_timeline_events
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT assignee, '' FROM github_timeline_events
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT reviewer, '' FROM github_timeline_events
+		WHERE host = :host AND owner = :owner AND repository = :repository AND reviewer NOT LIKE '%/%'
+		UNION SELECT author, '' FROM github_commits
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT committer, '' FROM github_commits
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT login, '' FROM github_metrics_stargazers
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT login, '' FROM github_metrics_watchers
+		WHERE host = :host AND owner = :owner AND repository = :repository
+		UNION SELECT split_part(fork, '/', 1), '' FROM github_metrics_forks
+		WHERE host = :host AND owner = :owner AND repository = :repository
+	)
+	SELECT login, BOOL_OR(type = 'Bot') AS bot FROM seen
+	WHERE login <> '' AND NOT EXISTS (
+		SELECT 1 FROM github_users u
+		WHERE u.host = :host AND u.login = seen.login AND u.collected_at >= :before
+	)
+	GROUP BY login
+	ORDER BY login`
+
+// GetStaleLogins returns the logins seen in the data of a repository that have
+// no profile yet, or whose profile was collected before the given time.
+func (db *DatabaseImpl) GetStaleLogins(host string, owner string, repository string, before time.Time) ([]Login, error) {
+	logins := []Login{}
+
+	params := map[string]interface{}{
+		"host":       host,
+		"owner":      owner,
+		"repository": repository,
+		"before":     before,
+	}
+
+	query, err := db.client.PrepareNamed(selectSeenLogins)
+	if err != nil {
+		return logins, err
+	}
+	defer query.Close()
+
+	err = query.Select(&logins, params)
+	if err != nil {
+		return logins, err
+	}
+
+	return logins, nil
+}
+
+// SaveUsers writes a page of user profiles in a single transaction.
+func (db *DatabaseImpl) SaveUsers(input []User) error {
+	tx, err := db.client.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// A batch may not touch the same row twice.
+	seen := map[string]bool{}
+	users := []User{}
+	for _, u := range input {
+		if seen[u.Host+"/"+u.Login] {
+			continue
+		}
+		seen[u.Host+"/"+u.Login] = true
+		users = append(users, u)
+	}
+
+	err = execBatch(tx, insertUser, users)
+	if err != nil {
+		return fmt.Errorf("could not add users: %v", err)
+	}
+
+	return tx.Commit()
+}
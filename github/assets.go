@@ -0,0 +1,129 @@
+package github
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/eveldcorp/devrel-github/database"
+)
+
+// The operating systems in asset names, by the name they are stored as.
+var assetOperatingSystems = map[string]string{
+	"linux":   "linux",
+	"darwin":  "darwin",
+	"macos":   "darwin",
+	"osx":     "darwin",
+	"mac":     "darwin",
+	"windows": "windows",
+	"win":     "windows",
+	"win32":   "windows",
+	"win64":   "windows",
+	"freebsd": "freebsd",
+	"openbsd": "openbsd",
+	"netbsd":  "netbsd",
+	"solaris": "solaris",
+	"illumos": "illumos",
+	"android": "android",
+}
+
+// The architectures in asset names, by the name they are stored as.
+var assetArchitectures = map[string]string{
+	"amd64":   "amd64",
+	"x64":     "amd64",
+	"386":     "386",
+	"i386":    "386",
+	"i686":    "386",
+	"x86":     "386",
+	"arm64":   "arm64",
+	"aarch64": "arm64",
+	"arm":     "arm",
+	"armhf":   "arm",
+	"armel":   "arm",
+	"ppc64le": "ppc64le",
+	"ppc64":   "ppc64",
+	"s390x":   "s390x",
+	"riscv64": "riscv64",
+	"mips":    "mips",
+	"mipsle":  "mipsle",
+	"mips64":  "mips64",
+}
+
+// The operating system of packages that do not name it.
+var assetExtensions = map[string]string{
+	".deb": "linux",
+	".rpm": "linux",
+	".apk": "linux",
+	".msi": "windows",
+	".exe": "windows",
+	".dmg": "darwin",
+	".pkg": "darwin",
+}
+
+// A version like 1.5.0 or v1.5.0-rc1 in an asset name.
+var assetVersion = regexp.MustCompile(`(?i)(?:^|[^0-9a-z.])v?(\d+\.\d+(?:\.\d+)?(?:-(?:alpha|beta|rc|dev|pre)[0-9a-z.]*)?)`)
+
+// Parse the operating system, architecture and version from the name of an
+// asset like terraform_1.5.0_linux_amd64.zip. Parts that are not found are
+// empty.
+func parseAssetName(name string) (os string, arch string, version string) {
+	lower := strings.ToLower(name)
+
+	// x86_64 would otherwise be split in two.
+	lower = strings.NewReplacer("x86_64", "amd64", "x86-64", "amd64").Replace(lower)
+
+	for _, token := range strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if os == "" {
+			os = assetOperatingSystems[token]
+		}
+
+		if arch == "" {
+			arch = assetArchitectures[token]
+			if arch == "" && strings.HasPrefix(token, "armv") {
+				arch = "arm"
+			}
+		}
+	}
+
+	if os == "" {
+		for extension, o := range assetExtensions {
+			if strings.HasSuffix(lower, extension) {
+				os = o
+				break
+			}
+		}
+	}
+
+	if m := assetVersion.FindStringSubmatch(name); m != nil {
+		version = m[1]
+	}
+
+	return os, arch, version
+}
+
+// Map a queried release asset, the version falls back to the tag of the
+// release when the name of the asset has none.
+func mapReleaseAsset(host string, owner string, repository string, r GithubRelease, a GithubReleaseAsset, collectedAt time.Time) database.ReleaseAsset {
+	os, arch, version := parseAssetName(a.Name)
+	if version == "" {
+		version = strings.TrimPrefix(r.TagName, "v")
+	}
+
+	return database.ReleaseAsset{
+		ID:          a.ID,
+		Release:     r.ID,
+		Host:        host,
+		Owner:       owner,
+		Repository:  repository,
+		Name:        a.Name,
+		Downloads:   a.DownloadCount,
+		Size:        a.Size,
+		OS:          os,
+		Arch:        arch,
+		Version:     version,
+		CollectedAt: collectedAt,
+	}
+}
@@ -0,0 +1,33 @@
+package github
+
+import "testing"
+
+func TestParseAssetName(t *testing.T) {
+	cases := []struct {
+		name    string
+		os      string
+		arch    string
+		version string
+	}{
+		{name: "terraform_1.5.0_linux_amd64.zip", os: "linux", arch: "amd64", version: "1.5.0"},
+		{name: "terraform_1.5.0_darwin_arm64.zip", os: "darwin", arch: "arm64", version: "1.5.0"},
+		{name: "tool-v2.1.0-rc1-windows-386.exe", os: "windows", arch: "386", version: "2.1.0-rc1"},
+		{name: "tool-1.2-x86_64-unknown-linux-gnu.tar.gz", os: "linux", arch: "amd64", version: "1.2"},
+		{name: "tool_macOS_aarch64.tar.gz", os: "darwin", arch: "arm64", version: ""},
+		{name: "tool_1.0.0_linux_armv7.tar.gz", os: "linux", arch: "arm", version: "1.0.0"},
+		{name: "tool_1.0.0_amd64.deb", os: "linux", arch: "amd64", version: "1.0.0"},
+		{name: "Tool-3.0.0.dmg", os: "darwin", arch: "", version: "3.0.0"},
+		{name: "tool-setup.msi", os: "windows", arch: "", version: ""},
+		{name: "checksums.txt", os: "", arch: "", version: ""},
+		{name: "SHA256SUMS", os: "", arch: "", version: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			os, arch, version := parseAssetName(c.name)
+			if os != c.os || arch != c.arch || version != c.version {
+				t.Errorf("got %q, %q, %q, want %q, %q, %q", os, arch, version, c.os, c.arch, c.version)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/oauth2"
+)
+
+// The lifetime of the JWT of a GitHub App, GitHub allows at most 10 minutes.
+const jwtLifetime = 9 * time.Minute
+
+// Create a token source for every credential of the options.
+func (o Options) sources(baseURL string, client *http.Client) ([]oauth2.TokenSource, error) {
+	sources := []oauth2.TokenSource{}
+
+	for _, t := range o.Tokens {
+		if t == "" {
+			continue
+		}
+
+		sources = append(sources, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: t}))
+	}
+
+	if o.AppID != 0 {
+		key, err := parsePrivateKey(o.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key of GitHub App: %v", err)
+		}
+
+		if o.InstallationID == 0 {
+			return nil, fmt.Errorf("the GitHub App needs an installation id")
+		}
+
+		src := &installationTokenSource{
+			appID:          o.AppID,
+			installationID: o.InstallationID,
+			key:            key,
+			baseURL:        baseURL,
+			client:         client,
+		}
+
+		// Reuse the installation token until it expires.
+		sources = append(sources, oauth2.ReuseTokenSource(nil, src))
+	}
+
+	return sources, nil
+}
+
+// installationTokenSource exchanges a JWT signed with the private key of a
+// GitHub App for an installation token.
+type installationTokenSource struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	baseURL        string
+	client         *http.Client
+}
+
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.jwt(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("could not create JWT: %v", err)
+	}
+
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", s.baseURL, s.installationID)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not create installation token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("could not create installation token: %s", resp.Status)
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode installation token: %v", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: body.Token,
+		TokenType:   "Bearer",
+		Expiry:      body.ExpiresAt,
+	}, nil
+}
+
+// Create a JWT authenticating as the GitHub App, backdated to allow for clock
+// skew.
+func (s *installationTokenSource) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": fmt.Sprint(s.appID),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Parse a PEM encoded RSA private key in PKCS #1 or PKCS #8 form.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the private key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// Pool authenticates every request with the token that has the most remaining
+// rate limit for the resource of the request.
+type Pool struct {
+	next     http.RoundTripper
+	sources  []oauth2.TokenSource
+	governor *Governor
+	logger   hclog.Logger
+
+	lock sync.Mutex
+	last int
+}
+
+func NewPool(next http.RoundTripper, sources []oauth2.TokenSource, governor *Governor, logger hclog.Logger) *Pool {
+	return &Pool{
+		next:     next,
+		sources:  sources,
+		governor: governor,
+		logger:   logger.Named("Pool"),
+	}
+}
+
+// RoundTrip authenticates a copy of the request and sends it.
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(p.sources) == 0 {
+		return p.next.RoundTrip(req)
+	}
+
+	token, err := p.pick(resource(req))
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not modify the request, see http.RoundTripper.
+	clone := req.Clone(req.Context())
+	token.SetAuthHeader(clone)
+
+	return p.next.RoundTrip(clone)
+}
+
+// Pick the token with the most remaining rate limit. Tokens without a known
+// rate limit are preferred, and when every token is exhausted the one that
+// can be used again first is picked. A source that fails to provide a token,
+// like a GitHub App whose installation token could not be created, is
+// skipped.
+func (p *Pool) pick(resource string) (*oauth2.Token, error) {
+	// Getting a token may be a request, which must not hold up other requests.
+	p.lock.Lock()
+	last := p.last
+	p.lock.Unlock()
+
+	var best *oauth2.Token
+	var bestLimit Limit
+	var bestIndex int
+	var lastErr error
+
+	for n := range p.sources {
+		// Start after the last used token so that equal tokens are rotated.
+		i := (last + 1 + n) % len(p.sources)
+
+		token, err := p.sources[i].Token()
+		if err != nil {
+			p.logger.Warn("Could not get token, skipping it", "source", i, "error", err)
+			lastErr = err
+			continue
+		}
+
+		limit, ok := p.governor.Limit(fingerprint(authorization(token)), resource)
+		if !ok {
+			best = token
+			bestIndex = i
+			break
+		}
+
+		if best == nil || better(limit, bestLimit) {
+			best = token
+			bestLimit = limit
+			bestIndex = i
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no token available: %v", lastErr)
+	}
+
+	p.lock.Lock()
+	p.last = bestIndex
+	p.lock.Unlock()
+
+	return best, nil
+}
+
+// Check whether a rate limit leaves more room than another.
+func better(a Limit, b Limit) bool {
+	now := time.Now()
+	availableA := a.Available()
+	availableB := b.Available()
+
+	if availableA.After(now) || availableB.After(now) {
+		return availableA.Before(availableB)
+	}
+
+	return a.left(now) > b.left(now)
+}
+
+// The Authorization header of a token.
+func authorization(token *oauth2.Token) string {
+	return strings.TrimSpace(token.Type() + " " + token.AccessToken)
+}
@@ -0,0 +1,153 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/oauth2"
+)
+
+// A token source that always fails, like a GitHub App with a revoked key.
+type failingSource struct{}
+
+func (failingSource) Token() (*oauth2.Token, error) {
+	return nil, errors.New("could not create installation token: 401 Unauthorized")
+}
+
+func static(token string) oauth2.TokenSource {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+}
+
+func TestBetter(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name string
+		a    Limit
+		b    Limit
+		want bool
+	}{
+		{
+			name: "more remaining",
+			a:    Limit{Limit: 5000, Remaining: 4000, ResetAt: now.Add(time.Hour)},
+			b:    Limit{Limit: 5000, Remaining: 100, ResetAt: now.Add(time.Hour)},
+			want: true,
+		},
+		{
+			name: "less remaining",
+			a:    Limit{Limit: 5000, Remaining: 100, ResetAt: now.Add(time.Hour)},
+			b:    Limit{Limit: 5000, Remaining: 4000, ResetAt: now.Add(time.Hour)},
+			want: false,
+		},
+		{
+			name: "reset is full again",
+			a:    Limit{Limit: 5000, Remaining: 0, ResetAt: now.Add(-time.Minute)},
+			b:    Limit{Limit: 5000, Remaining: 4000, ResetAt: now.Add(time.Hour)},
+			want: true,
+		},
+		{
+			name: "available over exhausted",
+			a:    Limit{Limit: 5000, Remaining: 1, ResetAt: now.Add(time.Hour)},
+			b:    Limit{Limit: 5000, Remaining: 0, ResetAt: now.Add(time.Minute)},
+			want: true,
+		},
+		{
+			name: "exhausted resetting first",
+			a:    Limit{Limit: 5000, Remaining: 0, ResetAt: now.Add(time.Minute)},
+			b:    Limit{Limit: 5000, Remaining: 0, ResetAt: now.Add(time.Hour)},
+			want: true,
+		},
+		{
+			name: "blocked by a secondary rate limit",
+			a:    Limit{Limit: 5000, Remaining: 4000, ResetAt: now.Add(time.Hour), BlockedUntil: now.Add(time.Minute)},
+			b:    Limit{Limit: 5000, Remaining: 100, ResetAt: now.Add(time.Hour)},
+			want: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := better(c.a, c.b)
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestPoolPick(t *testing.T) {
+	governor := NewGovernor(nil, hclog.NewNullLogger())
+
+	// Record the rate limits of the tokens.
+	limits := map[string]int{"a": 100, "b": 4000}
+	for token, remaining := range limits {
+		governor.limits[key(fingerprint("Bearer "+token), "core")] = &Limit{
+			Limit:     5000,
+			Remaining: remaining,
+			ResetAt:   time.Now().Add(time.Hour),
+		}
+	}
+
+	pool := NewPool(nil, []oauth2.TokenSource{static("a"), failingSource{}, static("b")}, governor, hclog.NewNullLogger())
+
+	for i := 0; i < 3; i++ {
+		token, err := pool.pick("core")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if token.AccessToken != "b" {
+			t.Errorf("got token %q, want the token with the most remaining rate limit", token.AccessToken)
+		}
+	}
+
+	// A token without a known rate limit is preferred.
+	pool = NewPool(nil, []oauth2.TokenSource{static("a"), failingSource{}, static("c")}, governor, hclog.NewNullLogger())
+
+	token, err := pool.pick("core")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if token.AccessToken != "c" {
+		t.Errorf("got token %q, want the unused token", token.AccessToken)
+	}
+}
+
+func TestPoolPickFailing(t *testing.T) {
+	governor := NewGovernor(nil, hclog.NewNullLogger())
+	pool := NewPool(nil, []oauth2.TokenSource{failingSource{}}, governor, hclog.NewNullLogger())
+
+	_, err := pool.pick("core")
+	if err == nil {
+		t.Errorf("expected an error when no source provides a token")
+	}
+}
+
+func TestPoolRoundTrip(t *testing.T) {
+	var got string
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header.Get("Authorization")
+		return response(req.URL.String(), http.StatusOK, nil, "{}"), nil
+	})
+
+	governor := NewGovernor(nil, hclog.NewNullLogger())
+	pool := NewPool(next, []oauth2.TokenSource{failingSource{}, static("a")}, governor, hclog.NewNullLogger())
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r", nil)
+	_, err := pool.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != "Bearer a" {
+		t.Errorf("got Authorization %q", got)
+	}
+
+	if req.Header.Get("Authorization") != "" {
+		t.Errorf("the request was modified")
+	}
+}
@@ -0,0 +1,82 @@
+package github
+
+import (
+	"github.com/eveldcorp/devrel-github/database"
+)
+
+// Map the commits of a pullrequest.
+func mapPullrequestCommits(pullrequest string, nodes []GithubPullrequestCommit) []database.PullrequestCommit {
+	commits := []database.PullrequestCommit{}
+
+	for _, c := range nodes {
+		commits = append(commits, database.PullrequestCommit{
+			Pullrequest:     pullrequest,
+			SHA:             c.Commit.Oid,
+			MessageHeadline: c.Commit.MessageHeadline,
+			Author:          c.Commit.Author.User.Login,
+			AuthorName:      c.Commit.Author.Name,
+			AuthorEmail:     c.Commit.Author.Email,
+			AuthoredAt:      c.Commit.AuthoredDate,
+			CommittedAt:     c.Commit.CommittedDate,
+		})
+	}
+
+	return commits
+}
+
+// Map the check runs and status contexts of the head commit of a pullrequest.
+// Statuses that are not pending are completed, with their state as conclusion,
+// so that both kinds of checks can be counted alike.
+func mapChecks(pullrequest string, sha string, nodes []GithubCheckContext) []database.PullrequestCheck {
+	checks := []database.PullrequestCheck{}
+
+	for _, c := range nodes {
+		switch c.Typename {
+		case "CheckRun":
+			r := c.CheckRun
+			check := database.PullrequestCheck{
+				ID:          r.ID,
+				Pullrequest: pullrequest,
+				SHA:         sha,
+				Type:        "check_run",
+				Name:        r.Name,
+				App:         r.CheckSuite.App.Name,
+				Workflow:    r.CheckSuite.WorkflowRun.Workflow.Name,
+				Status:      r.Status,
+				Conclusion:  r.Conclusion,
+				URL:         r.DetailsURL,
+				StartedAt:   r.StartedAt,
+				CompletedAt: r.CompletedAt,
+			}
+
+			if !r.StartedAt.IsZero() && r.CompletedAt.After(r.StartedAt) {
+				check.Duration = int(r.CompletedAt.Sub(r.StartedAt).Seconds())
+			}
+
+			checks = append(checks, check)
+		case "StatusContext":
+			s := c.StatusContext
+			check := database.PullrequestCheck{
+				ID:          s.ID,
+				Pullrequest: pullrequest,
+				SHA:         sha,
+				Type:        "status",
+				Name:        s.Context,
+				Description: s.Description,
+				Status:      "COMPLETED",
+				Conclusion:  s.State,
+				URL:         s.TargetURL,
+				StartedAt:   s.CreatedAt,
+			}
+
+			if s.State == "PENDING" || s.State == "EXPECTED" {
+				check.Status = s.State
+				check.Conclusion = ""
+			}
+
+			checks = append(checks, check)
+		}
+	}
+
+	return checks
+}
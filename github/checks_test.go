@@ -0,0 +1,138 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/database"
+)
+
+// A check run that ran from started to completed.
+func checkRun(id string, status string, conclusion string, started time.Time, completed time.Time) GithubCheckContext {
+	c := GithubCheckContext{Typename: "CheckRun"}
+	c.CheckRun.ID = id
+	c.CheckRun.Name = "build"
+	c.CheckRun.Status = status
+	c.CheckRun.Conclusion = conclusion
+	c.CheckRun.StartedAt = started
+	c.CheckRun.CompletedAt = completed
+	c.CheckRun.DetailsURL = "https://github.com/o/r/runs/" + id
+	c.CheckRun.CheckSuite.App.Name = "GitHub Actions"
+	c.CheckRun.CheckSuite.WorkflowRun.Workflow.Name = "CI"
+	return c
+}
+
+// A commit status in state.
+func statusContext(id string, state string, created time.Time) GithubCheckContext {
+	c := GithubCheckContext{Typename: "StatusContext"}
+	c.StatusContext.ID = id
+	c.StatusContext.Context = "ci/jenkins"
+	c.StatusContext.State = state
+	c.StatusContext.Description = "Build " + state
+	c.StatusContext.TargetURL = "https://jenkins.example.com/" + id
+	c.StatusContext.CreatedAt = created
+	return c
+}
+
+func TestMapChecks(t *testing.T) {
+	started := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	completed := started.Add(90 * time.Second)
+
+	run := func(id string, status string, conclusion string, completedAt time.Time, duration int) database.PullrequestCheck {
+		return database.PullrequestCheck{
+			ID:          id,
+			Pullrequest: "PR_1",
+			SHA:         "abc",
+			Type:        "check_run",
+			Name:        "build",
+			App:         "GitHub Actions",
+			Workflow:    "CI",
+			Status:      status,
+			Conclusion:  conclusion,
+			URL:         "https://github.com/o/r/runs/" + id,
+			StartedAt:   started,
+			CompletedAt: completedAt,
+			Duration:    duration,
+		}
+	}
+
+	commitStatus := func(id string, state string, conclusion string) database.PullrequestCheck {
+		return database.PullrequestCheck{
+			ID:          id,
+			Pullrequest: "PR_1",
+			SHA:         "abc",
+			Type:        "status",
+			Name:        "ci/jenkins",
+			Description: "Build " + state,
+			Status:      state,
+			Conclusion:  conclusion,
+			URL:         "https://jenkins.example.com/" + id,
+			StartedAt:   started,
+		}
+	}
+
+	cases := []struct {
+		name  string
+		nodes []GithubCheckContext
+		want  []database.PullrequestCheck
+	}{
+		{
+			name:  "none",
+			nodes: []GithubCheckContext{},
+			want:  []database.PullrequestCheck{},
+		},
+		{
+			name:  "completed check run",
+			nodes: []GithubCheckContext{checkRun("1", "COMPLETED", "SUCCESS", started, completed)},
+			want:  []database.PullrequestCheck{run("1", "COMPLETED", "SUCCESS", completed, 90)},
+		},
+		{
+			name:  "running check run",
+			nodes: []GithubCheckContext{checkRun("2", "IN_PROGRESS", "", started, time.Time{})},
+			want:  []database.PullrequestCheck{run("2", "IN_PROGRESS", "", time.Time{}, 0)},
+		},
+		{
+			name:  "completed before started",
+			nodes: []GithubCheckContext{checkRun("3", "COMPLETED", "FAILURE", started, started.Add(-time.Second))},
+			want:  []database.PullrequestCheck{run("3", "COMPLETED", "FAILURE", started.Add(-time.Second), 0)},
+		},
+		{
+			name: "statuses",
+			nodes: []GithubCheckContext{
+				statusContext("4", "SUCCESS", started),
+				statusContext("5", "FAILURE", started),
+				statusContext("6", "PENDING", started),
+				statusContext("7", "EXPECTED", started),
+			},
+			want: []database.PullrequestCheck{
+				func() database.PullrequestCheck {
+					c := commitStatus("4", "SUCCESS", "SUCCESS")
+					c.Status = "COMPLETED"
+					return c
+				}(),
+				func() database.PullrequestCheck {
+					c := commitStatus("5", "FAILURE", "FAILURE")
+					c.Status = "COMPLETED"
+					return c
+				}(),
+				commitStatus("6", "PENDING", ""),
+				commitStatus("7", "EXPECTED", ""),
+			},
+		},
+		{
+			name:  "unknown type",
+			nodes: []GithubCheckContext{{Typename: "Commit"}},
+			want:  []database.PullrequestCheck{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := mapChecks("PR_1", "abc", c.nodes)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
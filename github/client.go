@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/eveldcorp/devrel-github/database"
@@ -10,47 +11,147 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/shurcooL/githubv4"
-	"golang.org/x/oauth2"
 )
 
 type Github interface {
-	QueryIssues(owner string, repository string, since time.Time, limit int) ([]database.Issue, error)
-	QueryPullrequests(owner string, repository string, since time.Time, limit int) ([]database.Pullrequest, error)
-	QueryReleases(owner string, repository string, limit int) ([]database.Release, error)
-	QueryMetrics(owner string, repository string) (database.Metrics, error)
+	Host() string
+	QueryRepositories(owner string) ([]database.Repository, error)
+	QueryIssues(owner string, repository string, since time.Time, limit int, checkpoint *database.Checkpoint, fn func(issues []database.Issue, checkpoint database.Checkpoint) error) error
+	QueryPullrequests(owner string, repository string, since time.Time, limit int, checkpoint *database.Checkpoint, fn func(pullrequests []database.Pullrequest, checkpoint database.Checkpoint) error) error
+	QueryDiscussions(owner string, repository string, since time.Time, limit int, checkpoint *database.Checkpoint, fn func(discussions []database.Discussion, checkpoint database.Checkpoint) error) error
+	QueryCommits(owner string, repository string, branches []string, since time.Time, heads map[string]string, limit int, fn func(branch string, commits []database.Commit, head string, orphaned []string) error) error
+	QueryReleases(owner string, repository string, since time.Time, limit int, fn func(releases []database.Release) error) error
+	QueryReleaseDownloads(owner string, repository string, fn func(assets []database.ReleaseAsset) error) error
+	QueryLabelDefinitions(owner string, repository string) ([]database.Label, error)
+	QueryMilestones(owner string, repository string) ([]database.Milestone, error)
+	QueryMetrics(owner string, repository string, periods []string) (database.Metrics, error)
+	QueryUsers(logins []database.Login, fn func(users []database.User) error) error
+	QueryOrganizationMembers(organization string) ([]string, error)
 }
 
 type GithubImpl struct {
-	v4     *githubv4.Client
-	v3     *githubv3.Client
-	logger hclog.Logger
+	ctx      context.Context
+	v4       *githubv4.Client
+	v3       *githubv3.Client
+	governor *Governor
+	host     string
+	logger   hclog.Logger
 }
 
-func New(token string, logger hclog.Logger) Github {
+func New(options Options, logger hclog.Logger) (Github, error) {
 	logger = logger.Named("Github")
 
-	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	endpoints, err := options.endpoints()
+	if err != nil {
+		return nil, err
+	}
 
 	retryClient := retryablehttp.NewClient()
 	retryClient.RetryMax = 10
 
+	transport, err := options.transport(retryClient.HTTPClient.Transport)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := options.sources(endpoints.rest, &http.Client{Transport: transport})
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for rate limits to reset instead of failing, and authenticate every
+	// attempt with the token that has the most remaining rate limit.
+	governor := NewGovernor(transport, logger)
+	retryClient.HTTPClient.Transport = NewPool(governor, sources, governor, logger)
+	retryClient.CheckRetry = governor.CheckRetry
+	retryClient.Backoff = governor.Backoff
+
 	httpClient := retryClient.StandardClient()
-	httpClient.Transport = &oauth2.Transport{
-		Base:   httpClient.Transport,
-		Source: oauth2.ReuseTokenSource(nil, src),
+
+	v4 := githubv4.NewEnterpriseClient(endpoints.graphql, httpClient)
+	v3, err := githubv3.NewEnterpriseClient(endpoints.rest, endpoints.upload, httpClient)
+	if err != nil {
+		return nil, err
 	}
 
-	v4 := githubv4.NewClient(httpClient)
-	v3 := githubv3.NewClient(httpClient)
+	logger.Debug("Using GitHub", "host", endpoints.host, "rest", endpoints.rest, "graphql", endpoints.graphql)
+
+	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	return &GithubImpl{
-		v4:     v4,
-		v3:     v3,
-		logger: logger,
+		ctx:      ctx,
+		v4:       v4,
+		v3:       v3,
+		governor: governor,
+		host:     endpoints.host,
+		logger:   logger,
+	}, nil
+}
+
+// Host returns the host of the GitHub instance, which is stored with the data.
+func (g *GithubImpl) Host() string {
+	return g.host
+}
+
+func (g *GithubImpl) QueryRepositories(owner string) ([]database.Repository, error) {
+	var query struct {
+		RepositoryOwner struct {
+			Repositories struct {
+				Nodes      []GithubRepository
+				PageInfo   PageInfo
+				TotalCount int
+			} `graphql:"repositories(first: 100, after: $cursor, orderBy: { field: NAME, direction: ASC })"`
+		} `graphql:"repositoryOwner(login: $owner)"`
+		RateLimit RateLimit
+	}
+
+	variables := map[string]interface{}{
+		"owner":  githubv4.String(owner),
+		"cursor": (*githubv4.String)(nil),
 	}
+
+	page := 0
+	repositories := []database.Repository{}
+
+	for {
+		g.logger.Debug("Querying repositories", "owner", owner, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
+		if err != nil {
+			return repositories, err
+		}
+
+		// Process repositories
+		for _, r := range query.RepositoryOwner.Repositories.Nodes {
+			repository := database.Repository{
+				Owner:      r.Owner.Login,
+				Name:       r.Name,
+				IsArchived: r.IsArchived,
+				IsFork:     r.IsFork,
+			}
+
+			repositories = append(repositories, repository)
+		}
+
+		if !query.RepositoryOwner.Repositories.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(query.RepositoryOwner.Repositories.PageInfo.EndCursor)
+		page++
+	}
+
+	return repositories, nil
 }
 
-func (g *GithubImpl) QueryIssues(owner string, repository string, since time.Time, limit int) ([]database.Issue, error) {
+// QueryIssues queries the issues updated since, calling fn with the issues of
+// every page as soon as the page has been queried. When checkpoint is not nil
+// the query resumes from it, fn is passed the checkpoint to store together
+// with the issues, and additional comments are passed page by page as
+// partial issues instead of being collected in memory.
+func (g *GithubImpl) QueryIssues(owner string, repository string, since time.Time, limit int, checkpoint *database.Checkpoint, fn func(issues []database.Issue, checkpoint database.Checkpoint) error) error {
 	var query struct {
 		Repository struct {
 			Issues struct {
@@ -63,125 +164,129 @@ func (g *GithubImpl) QueryIssues(owner string, repository string, since time.Tim
 	}
 
 	variables := map[string]interface{}{
-		"owner":      githubv4.String(owner),
-		"repository": githubv4.String(repository),
-		"since":      githubv4.DateTime{Time: since},
-		"cursor":     (*githubv4.String)(nil),
+		"owner":          githubv4.String(owner),
+		"repository":     githubv4.String(repository),
+		"since":          githubv4.DateTime{Time: since},
+		"cursor":         (*githubv4.String)(nil),
+		"issueItemTypes": issueItemTypes,
 	}
 
-	var ratelimit RateLimit
-
+	cursor := ""
 	page := 0
-	issues := []database.Issue{}
+	count := 0
+
+	// Resume an interrupted query.
+	resume := database.Checkpoint{}
+	if checkpoint != nil && checkpoint.Cursor != "" {
+		g.logger.Info("Resuming issues", "owner", owner, "repository", repository, "page", checkpoint.Page)
+		resume = *checkpoint
+		cursor = resume.Cursor
+		page = resume.Page
+		variables["cursor"] = githubv4.String(cursor)
+	}
 
 	for {
 		g.logger.Debug("Querying issues", "owner", owner, "repository", repository, "page", page)
-		err := g.v4.Query(context.Background(), &query, variables)
+		err := g.v4.Query(g.ctx, &query, variables)
 		if err != nil {
-			return issues, err
+			return err
 		}
 
-		if ratelimit.Cost > ratelimit.Remaining {
-			return issues, fmt.Errorf("the query would exceed the current rate limit")
+		g.logger.Debug("Query cost", "cost", query.RateLimit.Cost, "remaining", query.RateLimit.Remaining, "reset", query.RateLimit.ResetAt.Format(time.RFC3339))
+
+		current := database.Checkpoint{
+			Host:       g.host,
+			Owner:      owner,
+			Repository: repository,
+			Kind:       "issues",
+			Since:      since,
+			Cursor:     cursor,
+			Page:       page,
 		}
 
+		issues := []database.Issue{}
+
 		// Process issues
 		for _, i := range query.Repository.Issues.Nodes {
-			issue := database.Issue{
-				ID:                i.ID,
-				Owner:             owner,
-				Repository:        repository,
-				Author:            i.Author.Login,
-				AuthorAssociation: i.AuthorAssociation,
-				Title:             i.Title,
-				Body:              i.Body,
-				CreatedAt:         i.CreatedAt,
-				PublishedAt:       i.PublishedAt,
-				UpdatedAt:         i.UpdatedAt,
-				LastEditedAt:      i.LastEditedAt,
-				ClosedAt:          i.ClosedAt,
-				State:             i.State,
-				Locked:            i.Locked,
-				Closed:            i.Closed,
-				Reactions:         []database.IssueReaction{},
-				Comments:          []database.IssueComment{},
-				Labels:            database.StringArray{},
+			// Query the following pages of nested connections
+			err := g.completeIssue(&i)
+			if err != nil {
+				return err
 			}
 
-			timelineItems := i.TimelineItems.Nodes
-			if len(timelineItems) > 0 {
-				// Get the last actor that closed the issue
-				issue.ClosedBy = timelineItems[len(timelineItems)-1].IssueTimelineItemsConnection.Actor.Login
-			}
-
-			// Reactions
-			for _, r := range i.ReactionGroups {
-				reaction := database.IssueReaction{
-					Reaction: r.Content,
-					Count:    r.Users.TotalCount,
-				}
-				issue.Reactions = append(issue.Reactions, reaction)
-			}
-
-			// Labels
-			for _, l := range i.Labels.Nodes {
-				issue.Labels = append(issue.Labels, l.Name)
+			issue := mapIssue(g.host, owner, repository, i)
+			if issue.Truncated {
+				g.logger.Warn("Issue is truncated", "owner", owner, "repository", repository, "number", i.Number)
 			}
 
 			// Query additional comments
 			if i.Comments.PageInfo.HasNextPage {
 				g.logger.Debug("Need to query additional comments")
-				comments, err := g.QueryIssueComments(owner, repository, i.Number, i.Comments.PageInfo.EndCursor)
-				if err != nil {
-					return issues, err
-				}
-				i.Comments.Nodes = append(i.Comments.Nodes, comments...)
-			}
-			// Comments
-			for _, c := range i.Comments.Nodes {
-				comment := database.IssueComment{
-					ID:                c.ID,
-					Author:            c.Author.Login,
-					AuthorAssociation: c.AuthorAssociation,
-					Body:              c.Body,
-					CreatedAt:         c.CreatedAt,
-					LastEditedAt:      c.LastEditedAt,
-					PublishedAt:       c.PublishedAt,
-					UpdatedAt:         c.UpdatedAt,
+
+				commentsCursor := i.Comments.PageInfo.EndCursor
+				commentsPage := 1
+				if resume.Number == i.Number && resume.CommentsCursor != "" {
+					commentsCursor = resume.CommentsCursor
+					commentsPage = resume.CommentsPage
 				}
 
-				// Comment reactions
-				for _, cr := range c.ReactionGroups {
-					reaction := database.IssueCommentReaction{
-						Content: cr.Content,
-						Count:   cr.Users.TotalCount,
+				err := g.QueryIssueComments(owner, repository, i.Number, commentsCursor, commentsPage, func(comments []GithubComment, next string, nextPage int) error {
+					if checkpoint == nil {
+						issue.Comments = append(issue.Comments, mapIssueComments(comments)...)
+						return nil
 					}
-					comment.Reactions = append(comment.Reactions, reaction)
+
+					partial := issue
+					partial.Comments = mapIssueComments(comments)
+
+					nested := current
+					nested.Number = i.Number
+					nested.CommentsCursor = next
+					nested.CommentsPage = nextPage
+
+					return fn([]database.Issue{partial}, nested)
+				})
+				if err != nil {
+					return err
 				}
-				issue.Comments = append(issue.Comments, comment)
 			}
 
 			issues = append(issues, issue)
 
-			if len(issues) == limit {
-				return issues, nil
+			count++
+			if count == limit {
+				break
 			}
 		}
 
-		if !query.Repository.Issues.PageInfo.HasNextPage {
+		cursor = query.Repository.Issues.PageInfo.EndCursor
+		page++
+
+		next := current
+		next.Cursor = cursor
+		next.Page = page
+
+		err = fn(issues, next)
+		if err != nil {
+			return err
+		}
+
+		if count == limit || !query.Repository.Issues.PageInfo.HasNextPage {
 			break
 		}
 
-		ratelimit = query.RateLimit
+		// Only the first page can be resumed part way.
+		resume = database.Checkpoint{}
 
-		variables["cursor"] = githubv4.String(query.Repository.Issues.PageInfo.EndCursor)
-		page++
+		variables["cursor"] = githubv4.String(cursor)
 	}
 
-	return issues, nil
+	return nil
 }
 
-func (g *GithubImpl) QueryIssueComments(owner string, repository string, number int, cursor string) ([]GithubComment, error) {
+// QueryIssueComments queries the comments of an issue after cursor, calling fn
+// with the comments of every page and the cursor and page to resume from.
+func (g *GithubImpl) QueryIssueComments(owner string, repository string, number int, cursor string, page int, fn func(comments []GithubComment, cursor string, page int) error) error {
 	var query struct {
 		Repository struct {
 			Issue struct {
@@ -201,30 +306,40 @@ func (g *GithubImpl) QueryIssueComments(owner string, repository string, number
 		"cursor":     githubv4.String(cursor),
 	}
 
-	page := 0
-	var comments []GithubComment
 	for {
-		g.logger.Debug("Querying issue comments", "owner", owner, "repository", repository, "page", page)
-		err := g.v4.Query(context.Background(), &query, variables)
+		g.logger.Debug("Querying issue comments", "owner", owner, "repository", repository, "number", number, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
 		if err != nil {
-			return comments, err
+			return err
 		}
 
 		// Process comments
-		comments = append(comments, query.Repository.Issue.Comments.Nodes...)
+		cursor = query.Repository.Issue.Comments.PageInfo.EndCursor
+		page++
+
+		err = fn(query.Repository.Issue.Comments.Nodes, cursor, page)
+		if err != nil {
+			return err
+		}
 
 		if !query.Repository.Issue.Comments.PageInfo.HasNextPage {
 			break
 		}
 
-		variables["cursor"] = githubv4.String(query.Repository.Issue.Comments.PageInfo.EndCursor)
-		page++
+		variables["cursor"] = githubv4.String(cursor)
 	}
 
-	return comments, nil
+	return nil
 }
 
-func (g *GithubImpl) QueryPullrequests(owner string, repository string, since time.Time, limit int) ([]database.Pullrequest, error) {
+// QueryPullrequests queries the pullrequests updated since, calling fn with the
+// pullrequests of every page as soon as the page has been queried. When
+// checkpoint is not nil the query resumes from it, fn is passed the checkpoint
+// to store together with the pullrequests, and additional comments are passed
+// page by page as partial pullrequests instead of being collected in memory.
+// When the limit is reached part way through a page, the checkpoint resumes
+// the page after the last pullrequest.
+func (g *GithubImpl) QueryPullrequests(owner string, repository string, since time.Time, limit int, checkpoint *database.Checkpoint, fn func(pullrequests []database.Pullrequest, checkpoint database.Checkpoint) error) error {
 	var query struct {
 		Repository struct {
 			PullRequests struct {
@@ -237,170 +352,182 @@ func (g *GithubImpl) QueryPullrequests(owner string, repository string, since ti
 	}
 
 	variables := map[string]interface{}{
-		"owner":      githubv4.String(owner),
-		"repository": githubv4.String(repository),
-		"cursor":     (*githubv4.String)(nil),
+		"owner":                githubv4.String(owner),
+		"repository":           githubv4.String(repository),
+		"cursor":               (*githubv4.String)(nil),
+		"pullrequestItemTypes": pullrequestItemTypes,
 	}
 
-	var ratelimit RateLimit
-
 	done := false
+	cursor := ""
 	page := 0
-	pullrequests := []database.Pullrequest{}
+	count := 0
+
+	// Resume an interrupted query.
+	resume := database.Checkpoint{}
+	if checkpoint != nil && (checkpoint.Cursor != "" || checkpoint.Number != 0) {
+		g.logger.Info("Resuming pullrequests", "owner", owner, "repository", repository, "page", checkpoint.Page)
+		resume = *checkpoint
+		cursor = resume.Cursor
+		page = resume.Page
+		if cursor != "" {
+			variables["cursor"] = githubv4.String(cursor)
+		}
+	}
 
 	for {
 		g.logger.Debug("Querying pullrequests", "owner", owner, "repository", repository, "page", page)
-		err := g.v4.Query(context.Background(), &query, variables)
+		err := g.v4.Query(g.ctx, &query, variables)
 		if err != nil {
-			return pullrequests, err
+			return err
+		}
+
+		g.logger.Debug("Query cost", "cost", query.RateLimit.Cost, "remaining", query.RateLimit.Remaining, "reset", query.RateLimit.ResetAt.Format(time.RFC3339))
+
+		current := database.Checkpoint{
+			Host:       g.host,
+			Owner:      owner,
+			Repository: repository,
+			Kind:       "pullrequests",
+			Since:      since,
+			Cursor:     cursor,
+			Page:       page,
 		}
 
-		if ratelimit.Cost > ratelimit.Remaining {
-			return pullrequests, fmt.Errorf("the query would exceed the current rate limit")
+		pullrequests := []database.Pullrequest{}
+
+		// Skip the pullrequests of a resumed page that have been written.
+		nodes := query.Repository.PullRequests.Nodes
+		numbers := []int{}
+		for _, n := range nodes {
+			numbers = append(numbers, n.Number)
 		}
+		nodes = nodes[resumeIndex(numbers, resume):]
+
+		// The last pullrequest written when the limit is reached part way through
+		// the page.
+		stopped := 0
 
 		// Process pullrequests
-		for _, p := range query.Repository.PullRequests.Nodes {
+		for i, p := range nodes {
 			if p.UpdatedAt.Before(since) {
 				done = true
 				break
 			}
 
-			pullrequest := database.Pullrequest{
-				ID:                p.ID,
-				Owner:             owner,
-				Repository:        repository,
-				Author:            p.Author.Login,
-				AuthorAssociation: p.AuthorAssociation,
-				Title:             p.Title,
-				Body:              p.Body,
-				CreatedAt:         p.CreatedAt,
-				PublishedAt:       p.PublishedAt,
-				UpdatedAt:         p.UpdatedAt,
-				LastEditedAt:      p.LastEditedAt,
-				ClosedAt:          p.ClosedAt,
-				Closed:            p.Closed,
-				MergedAt:          p.MergedAt,
-				Merged:            p.Merged,
-				Mergeable:         p.Mergeable,
-				MergedBy:          p.MergedBy.Login,
-				Additions:         p.Additions,
-				Deletions:         p.Deletions,
-				ChangedFiles:      p.ChangedFiles,
-				BaseRefName:       p.BaseRefName,
-				HeadRefName:       p.HeadRefName,
-				ReviewDecision:    p.ReviewDecision,
-				State:             p.State,
-				Locked:            p.Locked,
-				Labels:            database.StringArray{},
-				Reactions:         []database.PullrequestReaction{},
-				Comments:          []database.PullrequestComment{},
-				Files:             []database.PullrequestFile{},
-				Reviews:           []database.PullrequestReview{},
-			}
-
-			timelineItems := p.TimelineItems.Nodes
-			if len(timelineItems) > 0 {
-				// Get the last actor that closed the pullrequest
-				pullrequest.ClosedBy = timelineItems[len(timelineItems)-1].PullrequestTimelineItemsConnection.Actor.Login
-			}
-
-			// Reactions
-			for _, r := range p.ReactionGroups {
-				reaction := database.PullrequestReaction{
-					Reaction: r.Content,
-					Count:    r.Users.TotalCount,
-				}
-				pullrequest.Reactions = append(pullrequest.Reactions, reaction)
+			// Query the following pages of nested connections
+			err := g.completePullrequest(&p)
+			if err != nil {
+				return err
 			}
 
-			// Labels
-			for _, l := range p.Labels.Nodes {
-				pullrequest.Labels = append(pullrequest.Labels, l.Name)
+			pullrequest := mapPullrequest(g.host, owner, repository, p)
+			if pullrequest.Truncated {
+				g.logger.Warn("Pullrequest is truncated", "owner", owner, "repository", repository, "number", p.Number)
 			}
 
 			// Query additional comments
 			if p.Comments.PageInfo.HasNextPage {
 				g.logger.Debug("Need to query additional comments")
-				comments, err := g.QueryPullrequestComments(owner, repository, p.Number, p.Comments.PageInfo.EndCursor)
-				if err != nil {
-					return pullrequests, err
-				}
-				p.Comments.Nodes = append(p.Comments.Nodes, comments...)
-			}
-			// Comments
-			for _, c := range p.Comments.Nodes {
-				comment := database.PullrequestComment{
-					ID:                c.ID,
-					Author:            c.Author.Login,
-					AuthorAssociation: c.AuthorAssociation,
-					Body:              c.Body,
-					CreatedAt:         c.CreatedAt,
-					LastEditedAt:      c.LastEditedAt,
-					PublishedAt:       c.PublishedAt,
-					UpdatedAt:         c.UpdatedAt,
-				}
 
-				// Comment reactions
-				for _, cr := range c.ReactionGroups {
-					reaction := database.PullrequestCommentReaction{
-						Content: cr.Content,
-						Count:   cr.Users.TotalCount,
-					}
-					comment.Reactions = append(comment.Reactions, reaction)
-				}
-				pullrequest.Comments = append(pullrequest.Comments, comment)
-
-				// Reviews
-				for _, r := range p.Reviews.Nodes {
-					review := database.PullrequestReview{
-						Pullrequest:       p.ID,
-						Author:            r.Author.Login,
-						AuthorAssociation: r.AuthorAssociation,
-						Body:              r.Body,
-						State:             r.State,
-						CreatedAt:         r.CreatedAt,
-						PublishedAt:       r.PublishedAt,
-						LastEditedAt:      r.LastEditedAt,
-						UpdatedAt:         r.UpdatedAt,
-						SubmittedAt:       r.SubmittedAt,
-					}
-					pullrequest.Reviews = append(pullrequest.Reviews, review)
+				commentsCursor := p.Comments.PageInfo.EndCursor
+				commentsPage := 1
+				if resume.Number == p.Number && resume.CommentsCursor != "" {
+					commentsCursor = resume.CommentsCursor
+					commentsPage = resume.CommentsPage
 				}
 
-				// Files
-				for _, f := range p.Files.Nodes {
-					file := database.PullrequestFile{
-						Pullrequest: p.ID,
-						Path:        f.Path,
-						Additions:   f.Additions,
-						Deletions:   f.Deletions,
+				err := g.QueryPullrequestComments(owner, repository, p.Number, commentsCursor, commentsPage, func(comments []GithubComment, next string, nextPage int) error {
+					if checkpoint == nil {
+						pullrequest.Comments = append(pullrequest.Comments, mapPullrequestComments(comments)...)
+						return nil
 					}
-					pullrequest.Files = append(pullrequest.Files, file)
+
+					partial := pullrequest
+					partial.Comments = mapPullrequestComments(comments)
+
+					nested := current
+					nested.Number = p.Number
+					nested.CommentsCursor = next
+					nested.CommentsPage = nextPage
+
+					return fn([]database.Pullrequest{partial}, nested)
+				})
+				if err != nil {
+					return err
 				}
 			}
 
 			pullrequests = append(pullrequests, pullrequest)
 
-			if len(pullrequests) == limit {
-				return pullrequests, nil
+			count++
+			if count == limit {
+				if i < len(nodes)-1 {
+					stopped = p.Number
+				}
+				break
 			}
 		}
 
-		if done || !query.Repository.PullRequests.PageInfo.HasNextPage {
+		cursor = query.Repository.PullRequests.PageInfo.EndCursor
+		page++
+
+		next := current
+		next.Cursor = cursor
+		next.Page = page
+		if stopped != 0 {
+			// Resume the page after the last written pullrequest.
+			next = current
+			next.Number = stopped
+		}
+
+		err = fn(pullrequests, next)
+		if err != nil {
+			return err
+		}
+
+		if done || count == limit || !query.Repository.PullRequests.PageInfo.HasNextPage {
 			break
 		}
 
-		ratelimit = query.RateLimit
+		// Only the first page can be resumed part way.
+		resume = database.Checkpoint{}
 
-		variables["cursor"] = githubv4.String(query.Repository.PullRequests.PageInfo.EndCursor)
-		page++
+		variables["cursor"] = githubv4.String(cursor)
+	}
+
+	return nil
+}
+
+// The index of the first node of a resumed page to process, given the numbers
+// of the issues, pullrequests or discussions of the page. The nodes before
+// the number of the checkpoint have been written, as has the node itself
+// unless its comments are resumed. A page that no longer holds the number,
+// because it has been updated since, is processed from the start.
+func resumeIndex(numbers []int, resume database.Checkpoint) int {
+	if resume.Number == 0 {
+		return 0
+	}
+
+	for i, n := range numbers {
+		if n != resume.Number {
+			continue
+		}
+
+		if resume.CommentsCursor != "" {
+			return i
+		}
+
+		return i + 1
 	}
 
-	return pullrequests, nil
+	return 0
 }
 
-func (g *GithubImpl) QueryPullrequestComments(owner string, repository string, number int, cursor string) ([]GithubComment, error) {
+// QueryPullrequestComments queries the comments of a pullrequest after cursor,
+// calling fn with the comments of every page and the cursor and page to resume
+// from.
+func (g *GithubImpl) QueryPullrequestComments(owner string, repository string, number int, cursor string, page int, fn func(comments []GithubComment, cursor string, page int) error) error {
 	var query struct {
 		Repository struct {
 			PullRequest struct {
@@ -417,40 +544,45 @@ func (g *GithubImpl) QueryPullrequestComments(owner string, repository string, n
 		"owner":      githubv4.String(owner),
 		"repository": githubv4.String(repository),
 		"number":     githubv4.Int(number),
-		"cursor":     (*githubv4.String)(nil),
+		"cursor":     githubv4.String(cursor),
 	}
 
-	page := 0
-	var comments []GithubComment
 	for {
-		g.logger.Debug("Querying pullrequests comments", "owner", owner, "repository", repository, "page", page)
-		err := g.v4.Query(context.Background(), &query, variables)
+		g.logger.Debug("Querying pullrequests comments", "owner", owner, "repository", repository, "number", number, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
 		if err != nil {
-			return comments, err
+			return err
 		}
 
 		// Process comments
-		comments = append(comments, query.Repository.PullRequest.Comments.Nodes...)
+		cursor = query.Repository.PullRequest.Comments.PageInfo.EndCursor
+		page++
+
+		err = fn(query.Repository.PullRequest.Comments.Nodes, cursor, page)
+		if err != nil {
+			return err
+		}
 
 		if !query.Repository.PullRequest.Comments.PageInfo.HasNextPage {
 			break
 		}
 
-		variables["cursor"] = githubv4.String(query.Repository.PullRequest.Comments.PageInfo.EndCursor)
-		page++
+		variables["cursor"] = githubv4.String(cursor)
 	}
 
-	return comments, nil
+	return nil
 }
 
-func (g *GithubImpl) QueryReleases(owner string, repository string, limit int) ([]database.Release, error) {
+// QueryReleases queries the releases created since, newest first, calling fn
+// with the releases of every page as soon as the page has been queried.
+func (g *GithubImpl) QueryReleases(owner string, repository string, since time.Time, limit int, fn func(releases []database.Release) error) error {
 	var query struct {
 		Repository struct {
 			Releases struct {
 				Nodes      []GithubRelease
 				PageInfo   PageInfo
 				TotalCount int
-			} `graphql:"releases(first: 50, after: $cursor)"`
+			} `graphql:"releases(first: 50, after: $cursor, orderBy: { field: CREATED_AT, direction: DESC })"`
 		} `graphql:"repository(name: $repository, owner: $owner)"`
 		RateLimit RateLimit
 	}
@@ -461,27 +593,37 @@ func (g *GithubImpl) QueryReleases(owner string, repository string, limit int) (
 		"cursor":     (*githubv4.String)(nil),
 	}
 
-	var ratelimit RateLimit
-
 	done := false
 	page := 0
-	releases := []database.Release{}
+	count := 0
 
 	for {
 		g.logger.Debug("Querying releases", "owner", owner, "repository", repository, "page", page)
-		err := g.v4.Query(context.Background(), &query, variables)
+		err := g.v4.Query(g.ctx, &query, variables)
 		if err != nil {
-			return releases, err
+			return err
 		}
 
-		if ratelimit.Cost > ratelimit.Remaining {
-			return releases, fmt.Errorf("the query would exceed the current rate limit")
-		}
+		g.logger.Debug("Query cost", "cost", query.RateLimit.Cost, "remaining", query.RateLimit.Remaining, "reset", query.RateLimit.ResetAt.Format(time.RFC3339))
+
+		releases := []database.Release{}
 
 		// Process releases
 		for _, r := range query.Repository.Releases.Nodes {
+			if r.CreatedAt.Before(since) {
+				done = true
+				break
+			}
+
+			// Query the following pages of nested connections
+			err := g.completeRelease(&r)
+			if err != nil {
+				return err
+			}
+
 			release := database.Release{
 				ID:           r.ID,
+				Host:         g.host,
 				Owner:        owner,
 				Repository:   repository,
 				Name:         r.Name,
@@ -493,99 +635,205 @@ func (g *GithubImpl) QueryReleases(owner string, repository string, limit int) (
 				Assets:       []database.ReleaseAsset{},
 			}
 
+			collectedAt := time.Now().UTC().Round(0)
 			for _, a := range r.ReleaseAssets.Nodes {
-				asset := database.ReleaseAsset{
-					ID:         a.ID,
-					Release:    r.ID,
-					Owner:      owner,
-					Repository: repository,
-					Name:       a.Name,
-					Downloads:  a.DownloadCount,
-					Size:       a.Size,
-				}
+				release.Assets = append(release.Assets, mapReleaseAsset(g.host, owner, repository, r, a, collectedAt))
+			}
 
-				release.Assets = append(release.Assets, asset)
+			// GitHub may return fewer nodes than it counts, even when paginating.
+			release.Truncated = len(r.ReleaseAssets.Nodes) < r.ReleaseAssets.TotalCount
+			if release.Truncated {
+				g.logger.Warn("Release is truncated", "owner", owner, "repository", repository, "tag", r.TagName)
 			}
 
 			releases = append(releases, release)
+
+			count++
+			if count == limit {
+				return fn(releases)
+			}
+		}
+
+		err = fn(releases)
+		if err != nil {
+			return err
 		}
 
 		if done || !query.Repository.Releases.PageInfo.HasNextPage {
 			break
 		}
 
-		ratelimit = query.RateLimit
-
 		variables["cursor"] = githubv4.String(query.Repository.Releases.PageInfo.EndCursor)
 		page++
 	}
 
-	return releases, nil
+	return nil
 }
 
-func (g *GithubImpl) QueryMetrics(owner string, repository string) (database.Metrics, error) {
-	ctx := context.Background()
-
-	var metrics database.Metrics
-	metrics.Owner = owner
-	metrics.Repository = repository
-
-	var page int
-
-	g.logger.Debug("Querying traffic clones", "owner", owner, "repository", repository)
-	tc, _, err := g.v3.Repositories.ListTrafficClones(ctx, owner, repository, &githubv3.TrafficBreakdownOptions{})
-	if err != nil {
-		return metrics, fmt.Errorf("could not query traffic clones: %v+", err)
+// QueryReleaseDownloads queries only the download counts of the assets of
+// every release, calling fn with the assets of every page of releases.
+func (g *GithubImpl) QueryReleaseDownloads(owner string, repository string, fn func(assets []database.ReleaseAsset) error) error {
+	var query struct {
+		Repository struct {
+			Releases struct {
+				Nodes []struct {
+					ID            string
+					ReleaseAssets struct {
+						Nodes []struct {
+							ID            string
+							DownloadCount int
+						}
+						PageInfo PageInfo
+					} `graphql:"releaseAssets(first: 100)"`
+				}
+				PageInfo PageInfo
+			} `graphql:"releases(first: 100, after: $cursor)"`
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+		RateLimit RateLimit
 	}
 
-	metrics.Clones = database.TrafficClones{
-		Owner:      owner,
-		Repository: repository,
-		Date:       time.Now().UTC().Round(0),
-		Count:      tc.GetCount(),
-		Uniques:    tc.GetUniques(),
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+		"cursor":     (*githubv4.String)(nil),
 	}
 
-	g.logger.Debug("Querying forks", "owner", owner, "repository", repository)
-	page = 1
+	page := 0
+
 	for {
-		forks, response, err := g.v3.Repositories.ListForks(ctx, owner, repository, &githubv3.RepositoryListForksOptions{ListOptions: githubv3.ListOptions{Page: page, PerPage: 100}})
+		g.logger.Debug("Querying release downloads", "owner", owner, "repository", repository, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
 		if err != nil {
-			return metrics, fmt.Errorf("could not query forks: %v+", err)
+			return err
 		}
 
-		for _, f := range forks {
-			metrics.Forks = append(metrics.Forks, f.GetFullName())
+		g.logger.Debug("Query cost", "cost", query.RateLimit.Cost, "remaining", query.RateLimit.Remaining, "reset", query.RateLimit.ResetAt.Format(time.RFC3339))
+
+		assets := []database.ReleaseAsset{}
+		collectedAt := time.Now().UTC().Round(0)
+
+		// Process releases
+		for _, r := range query.Repository.Releases.Nodes {
+			for _, a := range r.ReleaseAssets.Nodes {
+				assets = append(assets, database.ReleaseAsset{
+					ID:          a.ID,
+					Release:     r.ID,
+					Host:        g.host,
+					Owner:       owner,
+					Repository:  repository,
+					Downloads:   a.DownloadCount,
+					CollectedAt: collectedAt,
+				})
+			}
+
+			// Query the following pages of assets
+			if r.ReleaseAssets.PageInfo.HasNextPage {
+				more, err := g.queryReleaseAssets(r.ID, r.ReleaseAssets.PageInfo.EndCursor)
+				if err != nil {
+					return err
+				}
+
+				for _, a := range more {
+					assets = append(assets, database.ReleaseAsset{
+						ID:          a.ID,
+						Release:     r.ID,
+						Host:        g.host,
+						Owner:       owner,
+						Repository:  repository,
+						Downloads:   a.DownloadCount,
+						CollectedAt: collectedAt,
+					})
+				}
+			}
 		}
 
-		if response.NextPage == 0 {
+		err = fn(assets)
+		if err != nil {
+			return err
+		}
+
+		if !query.Repository.Releases.PageInfo.HasNextPage {
 			break
 		}
 
+		variables["cursor"] = githubv4.String(query.Repository.Releases.PageInfo.EndCursor)
 		page++
 	}
 
-	g.logger.Debug("Querying stargazers", "owner", owner, "repository", repository)
-	page = 1
-	for {
-		stars, response, err := g.v3.Activity.ListStargazers(ctx, owner, repository, &githubv3.ListOptions{Page: page, PerPage: 100})
+	return nil
+}
+
+// QueryMetrics queries the stargazers, forks, watchers and traffic of a
+// repository, with the clones and views broken down per day or week for every
+// period.
+func (g *GithubImpl) QueryMetrics(owner string, repository string, periods []string) (database.Metrics, error) {
+	ctx := g.ctx
+
+	var metrics database.Metrics
+	metrics.Host = g.host
+	metrics.Owner = owner
+	metrics.Repository = repository
+	metrics.CollectedAt = time.Now().UTC().Round(0)
+
+	for _, per := range periods {
+		g.logger.Debug("Querying traffic clones", "owner", owner, "repository", repository, "per", per)
+		tc, _, err := g.v3.Repositories.ListTrafficClones(ctx, owner, repository, &githubv3.TrafficBreakdownOptions{Per: per})
 		if err != nil {
-			return metrics, fmt.Errorf("could not query stargazers: %v+", err)
+			return metrics, fmt.Errorf("could not query traffic clones: %v+", err)
 		}
 
-		for _, s := range stars {
-			metrics.Stars = append(metrics.Stars, s.GetUser().GetLogin())
+		for _, t := range trafficSeries(tc.Clones, per) {
+			metrics.Clones = append(metrics.Clones, database.TrafficClones{
+				Host:       g.host,
+				Owner:      owner,
+				Repository: repository,
+				Period:     per,
+				Date:       t.GetTimestamp().Time,
+				Count:      t.GetCount(),
+				Uniques:    t.GetUniques(),
+			})
 		}
 
-		if response.NextPage == 0 {
-			break
+		g.logger.Debug("Querying traffic views", "owner", owner, "repository", repository, "per", per)
+		tv, _, err := g.v3.Repositories.ListTrafficViews(ctx, owner, repository, &githubv3.TrafficBreakdownOptions{Per: per})
+		if err != nil {
+			return metrics, fmt.Errorf("could not query traffic views: %v+", err)
 		}
 
-		page++
+		for _, t := range trafficSeries(tv.Views, per) {
+			metrics.Views = append(metrics.Views, database.TrafficViews{
+				Host:       g.host,
+				Owner:      owner,
+				Repository: repository,
+				Period:     per,
+				Date:       t.GetTimestamp().Time,
+				Count:      t.GetCount(),
+				Uniques:    t.GetUniques(),
+			})
+		}
+	}
+
+	var err error
+	metrics.ForkRepositories, err = g.queryForks(owner, repository)
+	if err != nil {
+		return metrics, fmt.Errorf("could not query forks: %v+", err)
+	}
+
+	for _, f := range metrics.ForkRepositories {
+		metrics.Forks = append(metrics.Forks, f.Fork)
+	}
+
+	metrics.Stargazers, err = g.queryStargazers(owner, repository)
+	if err != nil {
+		return metrics, fmt.Errorf("could not query stargazers: %v+", err)
+	}
+
+	for _, s := range metrics.Stargazers {
+		metrics.Stars = append(metrics.Stars, s.Login)
 	}
 
 	g.logger.Debug("Querying watchers", "owner", owner, "repository", repository)
-	page = 1
+	page := 1
 	for {
 		watches, response, err := g.v3.Activity.ListWatchers(ctx, owner, repository, &githubv3.ListOptions{Page: page, PerPage: 100})
 		if err != nil {
@@ -594,6 +842,13 @@ func (g *GithubImpl) QueryMetrics(owner string, repository string) (database.Met
 
 		for _, w := range watches {
 			metrics.Watches = append(metrics.Watches, w.GetLogin())
+			metrics.Watchers = append(metrics.Watchers, database.Watcher{
+				Host:        g.host,
+				Owner:       owner,
+				Repository:  repository,
+				Login:       w.GetLogin(),
+				FirstSeenAt: metrics.CollectedAt,
+			})
 		}
 
 		if response.NextPage == 0 {
@@ -603,20 +858,6 @@ func (g *GithubImpl) QueryMetrics(owner string, repository string) (database.Met
 		page++
 	}
 
-	g.logger.Debug("Querying traffic views", "owner", owner, "repository", repository)
-	tv, _, err := g.v3.Repositories.ListTrafficViews(ctx, owner, repository, &githubv3.TrafficBreakdownOptions{})
-	if err != nil {
-		return metrics, fmt.Errorf("could not query traffic views: %v+", err)
-	}
-
-	metrics.Views = database.TrafficViews{
-		Owner:      owner,
-		Repository: repository,
-		Date:       time.Now().UTC().Round(0),
-		Count:      tv.GetCount(),
-		Uniques:    tv.GetUniques(),
-	}
-
 	g.logger.Debug("Querying traffic paths", "owner", owner, "repository", repository)
 	page = 1
 	for {
@@ -627,6 +868,7 @@ func (g *GithubImpl) QueryMetrics(owner string, repository string) (database.Met
 
 		for _, p := range paths {
 			metrics.Paths = append(metrics.Paths, database.TrafficPath{
+				Host:       g.host,
 				Owner:      owner,
 				Repository: repository,
 				Date:       time.Now().UTC().Round(0),
@@ -654,6 +896,7 @@ func (g *GithubImpl) QueryMetrics(owner string, repository string) (database.Met
 
 		for _, r := range referrers {
 			metrics.Referrers = append(metrics.Referrers, database.TrafficReferrer{
+				Host:       g.host,
 				Owner:      owner,
 				Repository: repository,
 				Date:       time.Now().UTC().Round(0),
@@ -670,3 +913,302 @@ func (g *GithubImpl) QueryMetrics(owner string, repository string) (database.Met
 
 	return metrics, nil
 }
+
+// Fill the days or weeks without traffic between the first and last reported
+// ones with zero counts, so that the series has no gaps.
+func trafficSeries(data []*githubv3.TrafficData, per string) []*githubv3.TrafficData {
+	step := 24 * time.Hour
+	if per == "week" {
+		step = 7 * step
+	}
+
+	reported := map[time.Time]*githubv3.TrafficData{}
+	var first, last time.Time
+	for _, d := range data {
+		t := d.GetTimestamp().UTC()
+		reported[t] = d
+
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	series := []*githubv3.TrafficData{}
+	if len(reported) == 0 {
+		return series
+	}
+
+	for t := first; !t.After(last); t = t.Add(step) {
+		d, ok := reported[t]
+		if !ok {
+			zero := 0
+			d = &githubv3.TrafficData{
+				Timestamp: &githubv3.Timestamp{Time: t},
+				Count:     &zero,
+				Uniques:   &zero,
+			}
+		}
+
+		series = append(series, d)
+	}
+
+	return series
+}
+
+// Map a queried issue, including the comments of the first page.
+func mapIssue(host string, owner string, repository string, i GithubIssue) database.Issue {
+	issue := database.Issue{
+		ID:                i.ID,
+		Host:              host,
+		Number:            i.Number,
+		Owner:             owner,
+		Repository:        repository,
+		Author:            i.Author.Login,
+		AuthorType:        i.Author.Typename,
+		IsBot:             i.Author.Typename == "Bot",
+		AuthorAssociation: i.AuthorAssociation,
+		Title:             i.Title,
+		Body:              i.Body,
+		CreatedAt:         i.CreatedAt,
+		PublishedAt:       i.PublishedAt,
+		UpdatedAt:         i.UpdatedAt,
+		LastEditedAt:      i.LastEditedAt,
+		ClosedAt:          i.ClosedAt,
+		State:             i.State,
+		Locked:            i.Locked,
+		Closed:            i.Closed,
+		Reactions:         []database.IssueReaction{},
+		Comments:          []database.IssueComment{},
+		Labels:            database.StringArray{},
+	}
+
+	timelineItems := i.TimelineItems.Nodes
+	if len(timelineItems) > 0 {
+		// Get the last actor that closed the issue
+		issue.ClosedBy = timelineItems[len(timelineItems)-1].IssueTimelineItemsConnection.Actor.Login
+	}
+
+	// Reactions
+	for _, r := range i.ReactionGroups {
+		reaction := database.IssueReaction{
+			Reaction: r.Content,
+			Count:    r.Users.TotalCount,
+		}
+		issue.Reactions = append(issue.Reactions, reaction)
+	}
+
+	// Labels
+	for _, l := range i.Labels.Nodes {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+
+	issue.ItemLabels = mapItemLabels(i.Labels.Nodes)
+	issue.Milestone = i.Milestone.ID
+	issue.Assignees = mapAssignees(i.Assignees.Nodes)
+
+	issue.Comments = mapIssueComments(i.Comments.Nodes)
+	issue.Events = mapTimeline(i.Timeline.Nodes)
+
+	// GitHub may return fewer nodes than it counts, even when paginating.
+	issue.Truncated = len(i.Labels.Nodes) < i.Labels.TotalCount ||
+		len(i.Assignees.Nodes) < i.Assignees.TotalCount
+
+	return issue
+}
+
+// Map queried issue comments.
+func mapIssueComments(nodes []GithubComment) []database.IssueComment {
+	comments := []database.IssueComment{}
+
+	for _, c := range nodes {
+		comment := database.IssueComment{
+			ID:                c.ID,
+			Author:            c.Author.Login,
+			AuthorType:        c.Author.Typename,
+			IsBot:             c.Author.Typename == "Bot",
+			AuthorAssociation: c.AuthorAssociation,
+			Body:              c.Body,
+			CreatedAt:         c.CreatedAt,
+			LastEditedAt:      c.LastEditedAt,
+			PublishedAt:       c.PublishedAt,
+			UpdatedAt:         c.UpdatedAt,
+		}
+
+		// Comment reactions
+		for _, cr := range c.ReactionGroups {
+			reaction := database.IssueCommentReaction{
+				Content: cr.Content,
+				Count:   cr.Users.TotalCount,
+			}
+			comment.Reactions = append(comment.Reactions, reaction)
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments
+}
+
+// Map a queried pullrequest, including the comments of the first page.
+func mapPullrequest(host string, owner string, repository string, p GithubPullrequest) database.Pullrequest {
+	pullrequest := database.Pullrequest{
+		ID:                p.ID,
+		Host:              host,
+		Number:            p.Number,
+		Owner:             owner,
+		Repository:        repository,
+		Author:            p.Author.Login,
+		AuthorType:        p.Author.Typename,
+		IsBot:             p.Author.Typename == "Bot",
+		AuthorAssociation: p.AuthorAssociation,
+		Title:             p.Title,
+		Body:              p.Body,
+		CreatedAt:         p.CreatedAt,
+		PublishedAt:       p.PublishedAt,
+		UpdatedAt:         p.UpdatedAt,
+		LastEditedAt:      p.LastEditedAt,
+		ClosedAt:          p.ClosedAt,
+		Closed:            p.Closed,
+		MergedAt:          p.MergedAt,
+		Merged:            p.Merged,
+		Mergeable:         p.Mergeable,
+		MergedBy:          p.MergedBy.Login,
+		Additions:         p.Additions,
+		Deletions:         p.Deletions,
+		ChangedFiles:      p.ChangedFiles,
+		BaseRefName:       p.BaseRefName,
+		HeadRefName:       p.HeadRefName,
+		ReviewDecision:    p.ReviewDecision,
+		State:             p.State,
+		Locked:            p.Locked,
+		Labels:            database.StringArray{},
+		Reactions:         []database.PullrequestReaction{},
+		Comments:          []database.PullrequestComment{},
+		Files:             []database.PullrequestFile{},
+		Reviews:           []database.PullrequestReview{},
+	}
+
+	timelineItems := p.TimelineItems.Nodes
+	if len(timelineItems) > 0 {
+		// Get the last actor that closed the pullrequest
+		pullrequest.ClosedBy = timelineItems[len(timelineItems)-1].PullrequestTimelineItemsConnection.Actor.Login
+	}
+
+	// Reactions
+	for _, r := range p.ReactionGroups {
+		reaction := database.PullrequestReaction{
+			Reaction: r.Content,
+			Count:    r.Users.TotalCount,
+		}
+		pullrequest.Reactions = append(pullrequest.Reactions, reaction)
+	}
+
+	// Labels
+	for _, l := range p.Labels.Nodes {
+		pullrequest.Labels = append(pullrequest.Labels, l.Name)
+	}
+
+	pullrequest.ItemLabels = mapItemLabels(p.Labels.Nodes)
+	pullrequest.Milestone = p.Milestone.ID
+	pullrequest.Assignees = mapAssignees(p.Assignees.Nodes)
+
+	pullrequest.Reviews = mapReviews(p.ID, p.Reviews.Nodes)
+
+	// Files
+	for _, f := range p.Files.Nodes {
+		file := database.PullrequestFile{
+			Pullrequest: p.ID,
+			Path:        f.Path,
+			Additions:   f.Additions,
+			Deletions:   f.Deletions,
+		}
+		pullrequest.Files = append(pullrequest.Files, file)
+	}
+
+	pullrequest.Comments = mapPullrequestComments(p.Comments.Nodes)
+	pullrequest.Events = mapPullrequestTimeline(p.Timeline.Nodes)
+	pullrequest.ReviewThreads = mapReviewThreads(p.ReviewThreads.Nodes)
+	pullrequest.Commits = mapPullrequestCommits(p.ID, p.Commits.Nodes)
+	pullrequest.Checks = []database.PullrequestCheck{}
+
+	checksTruncated := false
+	for _, c := range p.HeadCommit.Nodes {
+		rollup := c.Commit.StatusCheckRollup
+		pullrequest.HeadSHA = c.Commit.Oid
+		pullrequest.ChecksState = rollup.State
+		pullrequest.Checks = mapChecks(p.ID, c.Commit.Oid, rollup.Contexts.Nodes)
+		checksTruncated = len(rollup.Contexts.Nodes) < rollup.Contexts.TotalCount
+	}
+
+	// GitHub may return fewer nodes than it counts, even when paginating, and
+	// lists at most 3000 files and 250 commits.
+	pullrequest.Truncated = len(p.Labels.Nodes) < p.Labels.TotalCount ||
+		len(p.Assignees.Nodes) < p.Assignees.TotalCount ||
+		len(p.Reviews.Nodes) < p.Reviews.TotalCount ||
+		len(p.Files.Nodes) < p.Files.TotalCount ||
+		len(p.Files.Nodes) < p.ChangedFiles ||
+		len(p.Commits.Nodes) < p.Commits.TotalCount ||
+		checksTruncated
+
+	return pullrequest
+}
+
+// Map queried pullrequest reviews.
+func mapReviews(pullrequest string, nodes []GithubReview) []database.PullrequestReview {
+	reviews := []database.PullrequestReview{}
+
+	for _, r := range nodes {
+		review := database.PullrequestReview{
+			ID:                r.ID,
+			Pullrequest:       pullrequest,
+			Author:            r.Author.Login,
+			AuthorType:        r.Author.Typename,
+			IsBot:             r.Author.Typename == "Bot",
+			AuthorAssociation: r.AuthorAssociation,
+			Body:              r.Body,
+			State:             r.State,
+			CreatedAt:         r.CreatedAt,
+			PublishedAt:       r.PublishedAt,
+			LastEditedAt:      r.LastEditedAt,
+			UpdatedAt:         r.UpdatedAt,
+			SubmittedAt:       r.SubmittedAt,
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews
+}
+
+// Map queried pullrequest comments.
+func mapPullrequestComments(nodes []GithubComment) []database.PullrequestComment {
+	comments := []database.PullrequestComment{}
+
+	for _, c := range nodes {
+		comment := database.PullrequestComment{
+			ID:                c.ID,
+			Author:            c.Author.Login,
+			AuthorType:        c.Author.Typename,
+			IsBot:             c.Author.Typename == "Bot",
+			AuthorAssociation: c.AuthorAssociation,
+			Body:              c.Body,
+			CreatedAt:         c.CreatedAt,
+			LastEditedAt:      c.LastEditedAt,
+			PublishedAt:       c.PublishedAt,
+			UpdatedAt:         c.UpdatedAt,
+		}
+
+		// Comment reactions
+		for _, cr := range c.ReactionGroups {
+			reaction := database.PullrequestCommentReaction{
+				Content: cr.Content,
+				Count:   cr.Users.TotalCount,
+			}
+			comment.Reactions = append(comment.Reactions, reaction)
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments
+}
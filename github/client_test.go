@@ -0,0 +1,123 @@
+package github
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/database"
+	githubv3 "github.com/google/go-github/v50/github"
+)
+
+func TestResumeIndex(t *testing.T) {
+	numbers := []int{12, 7, 30, 4}
+
+	cases := []struct {
+		name   string
+		resume database.Checkpoint
+		want   int
+	}{
+		{
+			name:   "not resumed",
+			resume: database.Checkpoint{},
+			want:   0,
+		},
+		{
+			name:   "after the last written",
+			resume: database.Checkpoint{Number: 7},
+			want:   2,
+		},
+		{
+			name:   "last of the page",
+			resume: database.Checkpoint{Number: 4},
+			want:   4,
+		},
+		{
+			name:   "comments of a pullrequest",
+			resume: database.Checkpoint{Number: 30, CommentsCursor: "abc"},
+			want:   2,
+		},
+		{
+			name:   "number no longer on the page",
+			resume: database.Checkpoint{Number: 99},
+			want:   0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := resumeIndex(numbers, c.resume)
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func traffic(day string, count int, uniques int) *githubv3.TrafficData {
+	t, _ := time.Parse("2006-01-02", day)
+	return &githubv3.TrafficData{
+		Timestamp: &githubv3.Timestamp{Time: t},
+		Count:     &count,
+		Uniques:   &uniques,
+	}
+}
+
+func TestTrafficSeries(t *testing.T) {
+	cest := time.FixedZone("CEST", 2*60*60)
+
+	cases := []struct {
+		name string
+		data []*githubv3.TrafficData
+		per  string
+		want []string
+	}{
+		{
+			name: "empty",
+			data: []*githubv3.TrafficData{},
+			per:  "day",
+			want: []string{},
+		},
+		{
+			name: "days without gaps",
+			data: []*githubv3.TrafficData{traffic("2023-01-01", 3, 1), traffic("2023-01-02", 5, 2)},
+			per:  "day",
+			want: []string{"2023-01-01 3/1", "2023-01-02 5/2"},
+		},
+		{
+			name: "days with gaps",
+			data: []*githubv3.TrafficData{traffic("2023-01-04", 5, 2), traffic("2023-01-01", 3, 1)},
+			per:  "day",
+			want: []string{"2023-01-01 3/1", "2023-01-02 0/0", "2023-01-03 0/0", "2023-01-04 5/2"},
+		},
+		{
+			name: "weeks with gaps",
+			data: []*githubv3.TrafficData{traffic("2023-01-02", 3, 1), traffic("2023-01-23", 5, 2)},
+			per:  "week",
+			want: []string{"2023-01-02 3/1", "2023-01-09 0/0", "2023-01-16 0/0", "2023-01-23 5/2"},
+		},
+		{
+			name: "other time zone",
+			data: []*githubv3.TrafficData{
+				{Timestamp: &githubv3.Timestamp{Time: time.Date(2023, 1, 1, 2, 0, 0, 0, cest)}, Count: githubv3.Int(3), Uniques: githubv3.Int(1)},
+				traffic("2023-01-03", 5, 2),
+			},
+			per:  "day",
+			want: []string{"2023-01-01 3/1", "2023-01-02 0/0", "2023-01-03 5/2"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := []string{}
+			for _, d := range trafficSeries(c.data, c.per) {
+				got = append(got, fmt.Sprintf("%s %d/%d", d.GetTimestamp().UTC().Format("2006-01-02"), d.GetCount(), d.GetUniques()))
+			}
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/database"
+	githubv3 "github.com/google/go-github/v50/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// A Co-authored-by trailer of a commit message.
+var coauthorTrailer = regexp.MustCompile(`(?im)^co-authored-by:\s*(.*?)\s*<([^>]*)>\s*$`)
+
+// QueryCommits queries the commits of the default branch and of branches,
+// newest first, calling fn with the commits of every page as soon as the page
+// has been queried. The history of a branch is walked until the first commit
+// before since, or until it has yielded every commit that is new since
+// heads[branch], which was the head of the branch at the previous run. The
+// commits of that head that are no longer on the branch, because its history
+// was rewritten, are orphaned. Once a branch has been walked completely fn is
+// called with its new head and the orphaned commits, and no commits.
+func (g *GithubImpl) QueryCommits(owner string, repository string, branches []string, since time.Time, heads map[string]string, limit int, fn func(branch string, commits []database.Commit, head string, orphaned []string) error) error {
+	defaultBranch, err := g.queryDefaultBranch(owner, repository)
+	if err != nil {
+		return err
+	}
+
+	walk := []string{}
+	seen := map[string]bool{}
+	for _, b := range append([]string{defaultBranch}, branches...) {
+		if b == "" || seen[b] {
+			continue
+		}
+		seen[b] = true
+		walk = append(walk, b)
+	}
+
+	for _, branch := range walk {
+		err := g.queryBranchCommits(owner, repository, branch, since, heads[branch], limit, fn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Query the name of the default branch, which is empty for an empty
+// repository.
+func (g *GithubImpl) queryDefaultBranch(owner string, repository string) (string, error) {
+	var query struct {
+		Repository struct {
+			DefaultBranchRef struct {
+				Name string
+			}
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+	}
+
+	err := g.v4.Query(g.ctx, &query, variables)
+	if err != nil {
+		return "", err
+	}
+
+	return query.Repository.DefaultBranchRef.Name, nil
+}
+
+// Query the commits of a single branch that are new since the commit last.
+func (g *GithubImpl) queryBranchCommits(owner string, repository string, branch string, since time.Time, last string, limit int, fn func(branch string, commits []database.Commit, head string, orphaned []string) error) error {
+	// Walk the history of the head at the start, so that pushes while walking
+	// do not shift the pages.
+	var ref struct {
+		Repository struct {
+			Ref struct {
+				Target struct {
+					Oid string
+				}
+			} `graphql:"ref(qualifiedName: $branch)"`
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+	}
+
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+		"branch":     githubv4.String("refs/heads/" + branch),
+	}
+
+	err := g.v4.Query(g.ctx, &ref, variables)
+	if err != nil {
+		return err
+	}
+
+	head := ref.Repository.Ref.Target.Oid
+	if head == "" {
+		g.logger.Warn("Branch not found", "owner", owner, "repository", repository, "branch", branch)
+		return nil
+	}
+
+	if head == last {
+		g.logger.Debug("No new commits", "owner", owner, "repository", repository, "branch", branch)
+		return nil
+	}
+
+	// The history is ordered by date, so commits that a merge brings in may be
+	// older than the previous head. Take the commits to store from a comparison
+	// of both heads instead of walking until the previous head, which also
+	// finds the commits that a force push removed.
+	var added map[string]bool
+	orphaned := []string{}
+	if last != "" {
+		added, orphaned, err = g.compareHeads(owner, repository, last, head)
+		if err != nil {
+			return err
+		}
+
+		if added == nil {
+			g.logger.Warn("Previous head not found, walking the history again", "owner", owner, "repository", repository, "branch", branch, "head", last)
+		} else if len(orphaned) > 0 {
+			g.logger.Info("History was rewritten", "owner", owner, "repository", repository, "branch", branch, "head", last, "orphaned", len(orphaned))
+		}
+	}
+
+	var query struct {
+		Repository struct {
+			Object struct {
+				Commit struct {
+					History struct {
+						Nodes    []GithubCommit
+						PageInfo PageInfo
+					} `graphql:"history(first: 100, after: $cursor, since: $since)"`
+				} `graphql:"... on Commit"`
+			} `graphql:"object(oid: $oid)"`
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+		RateLimit RateLimit
+	}
+
+	variables = map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+		"oid":        githubv4.GitObjectID(head),
+		"cursor":     (*githubv4.String)(nil),
+		"since":      (*githubv4.GitTimestamp)(nil),
+	}
+
+	if !since.IsZero() {
+		variables["since"] = githubv4.GitTimestamp{Time: since}
+	}
+
+	remaining := len(added)
+	done := added != nil && remaining == 0
+	page := 0
+	count := 0
+
+	for !done {
+		g.logger.Debug("Querying commits", "owner", owner, "repository", repository, "branch", branch, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
+		if err != nil {
+			return err
+		}
+
+		g.logger.Debug("Query cost", "cost", query.RateLimit.Cost, "remaining", query.RateLimit.Remaining, "reset", query.RateLimit.ResetAt.Format(time.RFC3339))
+
+		commits := []database.Commit{}
+
+		// Process commits
+		for _, c := range query.Repository.Object.Commit.History.Nodes {
+			if added != nil {
+				if !added[c.Oid] {
+					continue
+				}
+				remaining--
+			}
+
+			commits = append(commits, mapCommit(g.host, owner, repository, branch, c))
+
+			count++
+			if added != nil && remaining == 0 {
+				done = true
+				break
+			}
+
+			if count == limit {
+				break
+			}
+		}
+
+		err = fn(branch, commits, "", nil)
+		if err != nil {
+			return err
+		}
+
+		if done || count == limit || !query.Repository.Object.Commit.History.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(query.Repository.Object.Commit.History.PageInfo.EndCursor)
+		page++
+	}
+
+	// A limited walk may have skipped commits, so the head is not advanced.
+	if limit > 0 && count == limit && !done {
+		return nil
+	}
+
+	return fn(branch, []database.Commit{}, head, orphaned)
+}
+
+// Compare the previous head last with head: the commits on the history of head
+// that are not on the history of last, and the commits of last that are no
+// longer on the history of head, which a force push removed. The added commits
+// are nil when last does not exist anymore.
+func (g *GithubImpl) compareHeads(owner string, repository string, last string, head string) (map[string]bool, []string, error) {
+	commits, behind, err := g.compareCommits(owner, repository, last, head)
+	if err != nil || commits == nil {
+		return nil, []string{}, err
+	}
+
+	added := map[string]bool{}
+	for _, sha := range commits {
+		added[sha] = true
+	}
+
+	orphaned := []string{}
+	if behind > 0 {
+		orphaned, _, err = g.compareCommits(owner, repository, head, last)
+		if err != nil {
+			return nil, []string{}, err
+		}
+	}
+
+	return added, orphaned, nil
+}
+
+// Query the commits on the history of head that are not on the history of
+// base, and the number of commits of base that are not on the history of head.
+// The commits are nil when base or head does not exist.
+func (g *GithubImpl) compareCommits(owner string, repository string, base string, head string) ([]string, int, error) {
+	commits := []string{}
+	behind := 0
+	page := 1
+
+	for {
+		comparison, response, err := g.v3.Repositories.CompareCommits(g.ctx, owner, repository, base, head, &githubv3.ListOptions{Page: page, PerPage: 100})
+		if err != nil {
+			var e *githubv3.ErrorResponse
+			if errors.As(err, &e) && e.Response.StatusCode == http.StatusNotFound {
+				return nil, 0, nil
+			}
+			return nil, 0, err
+		}
+
+		behind = comparison.GetBehindBy()
+		for _, c := range comparison.Commits {
+			commits = append(commits, c.GetSHA())
+		}
+
+		if response.NextPage == 0 {
+			break
+		}
+		page = response.NextPage
+	}
+
+	return commits, behind, nil
+}
+
+// Map a queried commit.
+func mapCommit(host string, owner string, repository string, branch string, c GithubCommit) database.Commit {
+	commit := database.Commit{
+		SHA:             c.Oid,
+		Host:            host,
+		Owner:           owner,
+		Repository:      repository,
+		Branch:          branch,
+		URL:             c.URL,
+		MessageHeadline: c.MessageHeadline,
+		Message:         c.Message,
+		Author:          c.Author.User.Login,
+		AuthorName:      c.Author.Name,
+		AuthorEmail:     c.Author.Email,
+		AuthoredAt:      c.AuthoredDate,
+		Committer:       c.Committer.User.Login,
+		CommitterName:   c.Committer.Name,
+		CommitterEmail:  c.Committer.Email,
+		CommittedAt:     c.CommittedDate,
+		Additions:       c.Additions,
+		Deletions:       c.Deletions,
+		Parents:         c.Parents.TotalCount,
+		Signed:          c.Signature.State != "",
+		SignatureValid:  c.Signature.IsValid,
+		SignatureState:  c.Signature.State,
+		Signer:          c.Signature.Signer.Login,
+		Coauthors:       []database.CommitCoauthor{},
+	}
+
+	if c.ChangedFilesIfAvailable != nil {
+		commit.ChangedFiles = *c.ChangedFilesIfAvailable
+	}
+
+	// Co-authors
+	seen := map[string]bool{}
+	for _, m := range coauthorTrailer.FindAllStringSubmatch(c.Message, -1) {
+		email := strings.ToLower(m[2])
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+
+		commit.Coauthors = append(commit.Coauthors, database.CommitCoauthor{
+			Name:  m[1],
+			Email: m[2],
+		})
+	}
+
+	return commit
+}
@@ -0,0 +1,313 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/database"
+	githubv3 "github.com/google/go-github/v50/github"
+	"github.com/hashicorp/go-hclog"
+	"github.com/shurcooL/githubv4"
+)
+
+func TestMapCommitCoauthors(t *testing.T) {
+	cases := []struct {
+		name    string
+		message string
+		want    []database.CommitCoauthor
+	}{
+		{
+			name:    "none",
+			message: "Fix the build",
+			want:    []database.CommitCoauthor{},
+		},
+		{
+			name:    "single",
+			message: "Fix the build\n\nCo-authored-by: Jane Doe <jane@example.com>",
+			want:    []database.CommitCoauthor{{Name: "Jane Doe", Email: "jane@example.com"}},
+		},
+		{
+			name:    "case and spacing",
+			message: "Fix the build\n\nco-authored-by:Jane Doe   <jane@example.com>  ",
+			want:    []database.CommitCoauthor{{Name: "Jane Doe", Email: "jane@example.com"}},
+		},
+		{
+			name:    "several",
+			message: "Fix the build\n\nCo-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: John Doe <john@example.com>",
+			want: []database.CommitCoauthor{
+				{Name: "Jane Doe", Email: "jane@example.com"},
+				{Name: "John Doe", Email: "john@example.com"},
+			},
+		},
+		{
+			name:    "duplicate email",
+			message: "Fix the build\n\nCo-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: Jane <JANE@example.com>",
+			want:    []database.CommitCoauthor{{Name: "Jane Doe", Email: "jane@example.com"}},
+		},
+		{
+			name:    "not a trailer",
+			message: "Mention Co-authored-by: Jane Doe <jane@example.com> in the docs",
+			want:    []database.CommitCoauthor{},
+		},
+		{
+			name:    "no email",
+			message: "Fix the build\n\nCo-authored-by: Jane Doe",
+			want:    []database.CommitCoauthor{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			commit := mapCommit("github.com", "o", "r", "main", GithubCommit{Oid: "abc", Message: c.message})
+			if !reflect.DeepEqual(commit.Coauthors, c.want) {
+				t.Errorf("got %+v, want %+v", commit.Coauthors, c.want)
+			}
+		})
+	}
+}
+
+// A fake GitHub of a single repository o/r. The REST comparisons of two
+// commits, by their base...head, return pages of commits, and a comparison
+// that is not listed is not found. The GraphQL API returns head as the head of
+// every branch, and pages of commits as its history.
+func fakeCommitsGithub(t *testing.T, comparisons map[string][][]string, behind map[string]int, head string, history [][]string) *GithubImpl {
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		headers := map[string]string{"Content-Type": "application/json"}
+
+		if req.URL.Path == "/graphql" {
+			var body struct {
+				Query     string
+				Variables map[string]interface{}
+			}
+			err := json.NewDecoder(req.Body).Decode(&body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Contains(body.Query, "ref(qualifiedName:") {
+				return response(req.URL.String(), http.StatusOK, headers, fmt.Sprintf(`{"data": {"repository": {"ref": {"target": {"oid": %q}}}}}`, head)), nil
+			}
+
+			page := 0
+			if cursor, ok := body.Variables["cursor"].(string); ok {
+				fmt.Sscan(cursor, &page)
+			}
+
+			nodes := []string{}
+			for _, sha := range history[page] {
+				nodes = append(nodes, fmt.Sprintf(`{"oid": %q}`, sha))
+			}
+
+			return response(req.URL.String(), http.StatusOK, headers, fmt.Sprintf(
+				`{"data": {"repository": {"object": {"history": {"nodes": [%s], "pageInfo": {"hasNextPage": %v, "endCursor": "%d"}}}}, "rateLimit": {"cost": 1, "remaining": 4999, "resetAt": "2023-01-01T00:00:00Z"}}}`,
+				strings.Join(nodes, ", "), page+1 < len(history), page+1,
+			)), nil
+		}
+
+		compare := strings.TrimPrefix(req.URL.Path, "/repos/o/r/compare/")
+		pages, ok := comparisons[compare]
+		if !ok {
+			return response(req.URL.String(), http.StatusNotFound, headers, `{"message": "Not Found"}`), nil
+		}
+
+		page := 1
+		fmt.Sscan(req.URL.Query().Get("page"), &page)
+
+		if page < len(pages) {
+			headers["Link"] = fmt.Sprintf(`<https://api.github.com/repos/o/r/compare/%s?page=%d>; rel="next"`, compare, page+1)
+		}
+
+		commits := []string{}
+		for _, sha := range pages[page-1] {
+			commits = append(commits, fmt.Sprintf(`{"sha": %q}`, sha))
+		}
+
+		return response(req.URL.String(), http.StatusOK, headers, fmt.Sprintf(`{"behind_by": %d, "commits": [%s]}`, behind[compare], strings.Join(commits, ", "))), nil
+	})
+
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next(req)
+		if resp != nil {
+			resp.Request = req
+		}
+		return resp, err
+	})}
+
+	return &GithubImpl{
+		ctx:    context.Background(),
+		v4:     githubv4.NewClient(client),
+		v3:     githubv3.NewClient(client),
+		host:   "github.com",
+		logger: hclog.NewNullLogger(),
+	}
+}
+
+func TestCompareHeads(t *testing.T) {
+	cases := []struct {
+		name        string
+		comparisons map[string][][]string
+		behind      map[string]int
+		added       map[string]bool
+		orphaned    []string
+	}{
+		{
+			name:        "fast forward",
+			comparisons: map[string][][]string{"old...new": {{"a", "new"}}},
+			added:       map[string]bool{"a": true, "new": true},
+			orphaned:    []string{},
+		},
+		{
+			name: "force push",
+			comparisons: map[string][][]string{
+				"old...new": {{"c", "new"}},
+				"new...old": {{"a", "b"}, {"old"}},
+			},
+			behind:   map[string]int{"old...new": 3},
+			added:    map[string]bool{"c": true, "new": true},
+			orphaned: []string{"a", "b", "old"},
+		},
+		{
+			name: "reset",
+			comparisons: map[string][][]string{
+				"old...new": {{}},
+				"new...old": {{"old"}},
+			},
+			behind:   map[string]int{"old...new": 1},
+			added:    map[string]bool{},
+			orphaned: []string{"old"},
+		},
+		{
+			name:        "previous head gone",
+			comparisons: map[string][][]string{},
+			added:       nil,
+			orphaned:    []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := fakeCommitsGithub(t, c.comparisons, c.behind, "new", nil)
+
+			added, orphaned, err := g.compareHeads("o", "r", "old", "new")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(added, c.added) {
+				t.Errorf("got added %v, want %v", added, c.added)
+			}
+
+			if !reflect.DeepEqual(orphaned, c.orphaned) {
+				t.Errorf("got orphaned %v, want %v", orphaned, c.orphaned)
+			}
+		})
+	}
+}
+
+func TestQueryBranchCommits(t *testing.T) {
+	// A merge m of a branch whose commits f1 and f2 are older than the previous
+	// head old, which sorts them after old in the history.
+	merge := [][]string{{"m", "c", "old", "f2"}, {"f1", "x"}}
+
+	cases := []struct {
+		name        string
+		last        string
+		limit       int
+		comparisons map[string][][]string
+		behind      map[string]int
+		history     [][]string
+		commits     []string
+		head        string
+		orphaned    []string
+	}{
+		{
+			name:        "first walk",
+			history:     merge,
+			comparisons: map[string][][]string{},
+			commits:     []string{"m", "c", "old", "f2", "f1", "x"},
+			head:        "m",
+			orphaned:    []string{},
+		},
+		{
+			name:        "merge of older commits",
+			last:        "old",
+			history:     merge,
+			comparisons: map[string][][]string{"old...m": {{"f1", "f2", "c", "m"}}},
+			commits:     []string{"m", "c", "f2", "f1"},
+			head:        "m",
+			orphaned:    []string{},
+		},
+		{
+			name:        "limited",
+			last:        "old",
+			limit:       3,
+			history:     merge,
+			comparisons: map[string][][]string{"old...m": {{"f1", "f2", "c", "m"}}},
+			commits:     []string{"m", "c", "f2"},
+			head:        "",
+		},
+		{
+			name:    "force push",
+			last:    "old",
+			history: [][]string{{"m", "c", "x"}},
+			comparisons: map[string][][]string{
+				"old...m": {{"c", "m"}},
+				"m...old": {{"old"}},
+			},
+			behind:   map[string]int{"old...m": 1},
+			commits:  []string{"m", "c"},
+			head:     "m",
+			orphaned: []string{"old"},
+		},
+		{
+			name:        "previous head gone",
+			last:        "old",
+			history:     [][]string{{"m", "c", "x"}},
+			comparisons: map[string][][]string{},
+			commits:     []string{"m", "c", "x"},
+			head:        "m",
+			orphaned:    []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := fakeCommitsGithub(t, c.comparisons, c.behind, "m", c.history)
+
+			commits := []string{}
+			head := ""
+			var orphaned []string
+			err := g.queryBranchCommits("o", "r", "main", time.Time{}, c.last, c.limit, func(branch string, page []database.Commit, h string, o []string) error {
+				for _, commit := range page {
+					commits = append(commits, commit.SHA)
+				}
+				if h != "" {
+					head = h
+					orphaned = o
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(commits, c.commits) {
+				t.Errorf("got commits %v, want %v", commits, c.commits)
+			}
+
+			if head != c.head {
+				t.Errorf("got head %q, want %q", head, c.head)
+			}
+
+			if !reflect.DeepEqual(orphaned, c.orphaned) {
+				t.Errorf("got orphaned %v, want %v", orphaned, c.orphaned)
+			}
+		})
+	}
+}
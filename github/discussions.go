@@ -0,0 +1,345 @@
+package github
+
+import (
+	"time"
+
+	"github.com/eveldcorp/devrel-github/database"
+	"github.com/shurcooL/githubv4"
+)
+
+// QueryDiscussions queries the discussions updated since, calling fn with the
+// discussions of every page as soon as the page has been queried. When
+// checkpoint is not nil the query resumes from it, fn is passed the checkpoint
+// to store together with the discussions, and additional comments are passed
+// page by page as partial discussions instead of being collected in memory.
+// When the limit is reached part way through a page, the checkpoint resumes
+// the page after the last discussion.
+func (g *GithubImpl) QueryDiscussions(owner string, repository string, since time.Time, limit int, checkpoint *database.Checkpoint, fn func(discussions []database.Discussion, checkpoint database.Checkpoint) error) error {
+	var query struct {
+		Repository struct {
+			Discussions struct {
+				Nodes      []GithubDiscussion
+				PageInfo   PageInfo
+				TotalCount int
+			} `graphql:"discussions(first: 25, after: $cursor, orderBy: { field: UPDATED_AT, direction: DESC })"`
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+		RateLimit RateLimit
+	}
+
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+		"cursor":     (*githubv4.String)(nil),
+	}
+
+	done := false
+	cursor := ""
+	page := 0
+	count := 0
+
+	// Resume an interrupted query.
+	resume := database.Checkpoint{}
+	if checkpoint != nil && (checkpoint.Cursor != "" || checkpoint.Number != 0) {
+		g.logger.Info("Resuming discussions", "owner", owner, "repository", repository, "page", checkpoint.Page)
+		resume = *checkpoint
+		cursor = resume.Cursor
+		page = resume.Page
+		if cursor != "" {
+			variables["cursor"] = githubv4.String(cursor)
+		}
+	}
+
+	for {
+		g.logger.Debug("Querying discussions", "owner", owner, "repository", repository, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
+		if err != nil {
+			return err
+		}
+
+		g.logger.Debug("Query cost", "cost", query.RateLimit.Cost, "remaining", query.RateLimit.Remaining, "reset", query.RateLimit.ResetAt.Format(time.RFC3339))
+
+		current := database.Checkpoint{
+			Host:       g.host,
+			Owner:      owner,
+			Repository: repository,
+			Kind:       "discussions",
+			Since:      since,
+			Cursor:     cursor,
+			Page:       page,
+		}
+
+		discussions := []database.Discussion{}
+
+		// Skip the discussions of a resumed page that have been written.
+		nodes := query.Repository.Discussions.Nodes
+		numbers := []int{}
+		for _, n := range nodes {
+			numbers = append(numbers, n.Number)
+		}
+		nodes = nodes[resumeIndex(numbers, resume):]
+
+		// The last discussion written when the limit is reached part way through
+		// the page.
+		stopped := 0
+
+		// Process discussions
+		for i, d := range nodes {
+			if d.UpdatedAt.Before(since) {
+				done = true
+				break
+			}
+
+			// Query the following pages of nested connections
+			err := g.completeDiscussion(&d)
+			if err != nil {
+				return err
+			}
+
+			discussion := mapDiscussion(g.host, owner, repository, d)
+			if discussion.Truncated {
+				g.logger.Warn("Discussion is truncated", "owner", owner, "repository", repository, "number", d.Number)
+			}
+
+			// Query additional comments
+			if d.Comments.PageInfo.HasNextPage {
+				g.logger.Debug("Need to query additional comments")
+
+				commentsCursor := d.Comments.PageInfo.EndCursor
+				commentsPage := 1
+				if resume.Number == d.Number && resume.CommentsCursor != "" {
+					commentsCursor = resume.CommentsCursor
+					commentsPage = resume.CommentsPage
+				}
+
+				err := g.QueryDiscussionComments(owner, repository, d.Number, commentsCursor, commentsPage, func(comments []GithubDiscussionComment, next string, nextPage int) error {
+					if checkpoint == nil {
+						discussion.Comments = append(discussion.Comments, mapDiscussionComments(comments)...)
+						return nil
+					}
+
+					partial := discussion
+					partial.Comments = mapDiscussionComments(comments)
+
+					nested := current
+					nested.Number = d.Number
+					nested.CommentsCursor = next
+					nested.CommentsPage = nextPage
+
+					return fn([]database.Discussion{partial}, nested)
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			discussions = append(discussions, discussion)
+
+			count++
+			if count == limit {
+				if i < len(nodes)-1 {
+					stopped = d.Number
+				}
+				break
+			}
+		}
+
+		cursor = query.Repository.Discussions.PageInfo.EndCursor
+		page++
+
+		next := current
+		next.Cursor = cursor
+		next.Page = page
+		if stopped != 0 {
+			// Resume the page after the last written discussion.
+			next = current
+			next.Number = stopped
+		}
+
+		err = fn(discussions, next)
+		if err != nil {
+			return err
+		}
+
+		if done || count == limit || !query.Repository.Discussions.PageInfo.HasNextPage {
+			break
+		}
+
+		// Only the first page can be resumed part way.
+		resume = database.Checkpoint{}
+
+		variables["cursor"] = githubv4.String(cursor)
+	}
+
+	return nil
+}
+
+// QueryDiscussionComments queries the comments of a discussion after cursor,
+// calling fn with the comments of every page and the cursor and page to resume
+// from.
+func (g *GithubImpl) QueryDiscussionComments(owner string, repository string, number int, cursor string, page int, fn func(comments []GithubDiscussionComment, cursor string, page int) error) error {
+	var query struct {
+		Repository struct {
+			Discussion struct {
+				Comments struct {
+					Nodes    []GithubDiscussionComment
+					PageInfo PageInfo
+				} `graphql:"comments(first: 25, after: $cursor)"`
+			} `graphql:"discussion(number: $number)"`
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+		RateLimit RateLimit
+	}
+
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+		"number":     githubv4.Int(number),
+		"cursor":     githubv4.String(cursor),
+	}
+
+	for {
+		g.logger.Debug("Querying discussion comments", "owner", owner, "repository", repository, "number", number, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
+		if err != nil {
+			return err
+		}
+
+		// Query the following pages of replies
+		err = g.completeReplies(query.Repository.Discussion.Comments.Nodes)
+		if err != nil {
+			return err
+		}
+
+		// Process comments
+		cursor = query.Repository.Discussion.Comments.PageInfo.EndCursor
+		page++
+
+		err = fn(query.Repository.Discussion.Comments.Nodes, cursor, page)
+		if err != nil {
+			return err
+		}
+
+		if !query.Repository.Discussion.Comments.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(cursor)
+	}
+
+	return nil
+}
+
+// Map a queried discussion, including the comments of the first page.
+func mapDiscussion(host string, owner string, repository string, d GithubDiscussion) database.Discussion {
+	discussion := database.Discussion{
+		ID:         d.ID,
+		Number:     d.Number,
+		Host:       host,
+		Owner:      owner,
+		Repository: repository,
+		Category: database.DiscussionCategory{
+			ID:           d.Category.ID,
+			Name:         d.Category.Name,
+			Slug:         d.Category.Slug,
+			Emoji:        d.Category.Emoji,
+			Description:  d.Category.Description,
+			IsAnswerable: d.Category.IsAnswerable,
+			CreatedAt:    d.Category.CreatedAt,
+			UpdatedAt:    d.Category.UpdatedAt,
+		},
+		CategoryID:        d.Category.ID,
+		Title:             d.Title,
+		Body:              d.Body,
+		Author:            d.Author.Login,
+		AuthorType:        d.Author.Typename,
+		IsBot:             d.Author.Typename == "Bot",
+		AuthorAssociation: d.AuthorAssociation,
+		CreatedAt:         d.CreatedAt,
+		PublishedAt:       d.PublishedAt,
+		UpdatedAt:         d.UpdatedAt,
+		LastEditedAt:      d.LastEditedAt,
+		ClosedAt:          d.ClosedAt,
+		Closed:            d.Closed,
+		Locked:            d.Locked,
+		UpvoteCount:       d.UpvoteCount,
+		IsAnswered:        d.IsAnswered,
+		Answer:            d.Answer.ID,
+		AnswerChosenAt:    d.AnswerChosenAt,
+		AnswerChosenBy:    d.AnswerChosenBy.Login,
+		Labels:            database.StringArray{},
+		Reactions:         []database.DiscussionReaction{},
+	}
+
+	// Reactions
+	for _, r := range d.ReactionGroups {
+		reaction := database.DiscussionReaction{
+			Reaction: r.Content,
+			Count:    r.Users.TotalCount,
+		}
+		discussion.Reactions = append(discussion.Reactions, reaction)
+	}
+
+	// Labels
+	for _, l := range d.Labels.Nodes {
+		discussion.Labels = append(discussion.Labels, l.Name)
+	}
+
+	discussion.Comments = mapDiscussionComments(d.Comments.Nodes)
+
+	// GitHub may return fewer nodes than it counts, even when paginating.
+	discussion.Truncated = len(d.Labels.Nodes) < d.Labels.TotalCount
+	for _, c := range d.Comments.Nodes {
+		if len(c.Replies.Nodes) < c.Replies.TotalCount {
+			discussion.Truncated = true
+		}
+	}
+
+	return discussion
+}
+
+// Map queried discussion comments and their replies.
+func mapDiscussionComments(nodes []GithubDiscussionComment) []database.DiscussionComment {
+	comments := []database.DiscussionComment{}
+
+	for _, c := range nodes {
+		comment := mapDiscussionReply(c.GithubDiscussionReply)
+
+		for _, r := range c.Replies.Nodes {
+			comment.Replies = append(comment.Replies, mapDiscussionReply(r))
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments
+}
+
+// Map a queried discussion comment or reply.
+func mapDiscussionReply(c GithubDiscussionReply) database.DiscussionComment {
+	comment := database.DiscussionComment{
+		ID:                c.ID,
+		Author:            c.Author.Login,
+		AuthorType:        c.Author.Typename,
+		IsBot:             c.Author.Typename == "Bot",
+		AuthorAssociation: c.AuthorAssociation,
+		Body:              c.Body,
+		CreatedAt:         c.CreatedAt,
+		PublishedAt:       c.PublishedAt,
+		UpdatedAt:         c.UpdatedAt,
+		LastEditedAt:      c.LastEditedAt,
+		IsAnswer:          c.IsAnswer,
+		UpvoteCount:       c.UpvoteCount,
+		Reactions:         []database.DiscussionCommentReaction{},
+		Replies:           []database.DiscussionComment{},
+	}
+
+	// Comment reactions
+	for _, r := range c.ReactionGroups {
+		reaction := database.DiscussionCommentReaction{
+			Content: r.Content,
+			Count:   r.Users.TotalCount,
+		}
+		comment.Reactions = append(comment.Reactions, reaction)
+	}
+
+	return comment
+}
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"time"
+
+	"github.com/eveldcorp/devrel-github/database"
+	"github.com/shurcooL/githubv4"
+)
+
+// QueryLabelDefinitions queries every label defined in a repository.
+func (g *GithubImpl) QueryLabelDefinitions(owner string, repository string) ([]database.Label, error) {
+	var query struct {
+		Repository struct {
+			Labels struct {
+				Nodes    []GithubRepositoryLabel
+				PageInfo PageInfo
+			} `graphql:"labels(first: 100, after: $cursor)"`
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+		RateLimit RateLimit
+	}
+
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+		"cursor":     (*githubv4.String)(nil),
+	}
+
+	labels := []database.Label{}
+	page := 0
+
+	for {
+		g.logger.Debug("Querying labels", "owner", owner, "repository", repository, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
+		if err != nil {
+			return labels, err
+		}
+
+		g.logger.Debug("Query cost", "cost", query.RateLimit.Cost, "remaining", query.RateLimit.Remaining, "reset", query.RateLimit.ResetAt.Format(time.RFC3339))
+
+		for _, l := range query.Repository.Labels.Nodes {
+			labels = append(labels, database.Label{
+				ID:          l.ID,
+				Host:        g.host,
+				Owner:       owner,
+				Repository:  repository,
+				Name:        l.Name,
+				Color:       l.Color,
+				Description: l.Description,
+				IsDefault:   l.IsDefault,
+				CreatedAt:   l.CreatedAt,
+				UpdatedAt:   l.UpdatedAt,
+			})
+		}
+
+		if !query.Repository.Labels.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(query.Repository.Labels.PageInfo.EndCursor)
+		page++
+	}
+
+	return labels, nil
+}
+
+// QueryMilestones queries every open and closed milestone of a repository,
+// with the number of open and closed issues and pullrequests in them.
+func (g *GithubImpl) QueryMilestones(owner string, repository string) ([]database.Milestone, error) {
+	var query struct {
+		Repository struct {
+			Milestones struct {
+				Nodes    []GithubMilestone
+				PageInfo PageInfo
+			} `graphql:"milestones(first: 50, after: $cursor)"`
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+		RateLimit RateLimit
+	}
+
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+		"cursor":     (*githubv4.String)(nil),
+	}
+
+	milestones := []database.Milestone{}
+	page := 0
+
+	for {
+		g.logger.Debug("Querying milestones", "owner", owner, "repository", repository, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
+		if err != nil {
+			return milestones, err
+		}
+
+		g.logger.Debug("Query cost", "cost", query.RateLimit.Cost, "remaining", query.RateLimit.Remaining, "reset", query.RateLimit.ResetAt.Format(time.RFC3339))
+
+		for _, m := range query.Repository.Milestones.Nodes {
+			milestones = append(milestones, database.Milestone{
+				ID:                 m.ID,
+				Host:               g.host,
+				Owner:              owner,
+				Repository:         repository,
+				Number:             m.Number,
+				Title:              m.Title,
+				Description:        m.Description,
+				URL:                m.URL,
+				State:              m.State,
+				DueOn:              m.DueOn,
+				CreatedAt:          m.CreatedAt,
+				UpdatedAt:          m.UpdatedAt,
+				ClosedAt:           m.ClosedAt,
+				ProgressPercentage: m.ProgressPercentage,
+				OpenIssues:         m.OpenIssues.TotalCount,
+				ClosedIssues:       m.ClosedIssues.TotalCount,
+				OpenPullrequests:   m.OpenPullrequests.TotalCount,
+				ClosedPullrequests: m.ClosedPullrequests.TotalCount,
+			})
+		}
+
+		if !query.Repository.Milestones.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(query.Repository.Milestones.PageInfo.EndCursor)
+		page++
+	}
+
+	return milestones, nil
+}
+
+// Map the labels of an issue or pullrequest.
+func mapItemLabels(nodes []GithubLabel) []database.ItemLabel {
+	labels := []database.ItemLabel{}
+
+	for _, l := range nodes {
+		labels = append(labels, database.ItemLabel{
+			Label: l.ID,
+			Name:  l.Name,
+		})
+	}
+
+	return labels
+}
+
+// Map the assignees of an issue or pullrequest.
+func mapAssignees(nodes []GithubActor) []database.ItemAssignee {
+	assignees := []database.ItemAssignee{}
+
+	for _, a := range nodes {
+		assignees = append(assignees, database.ItemAssignee{
+			Login: a.Login,
+		})
+	}
+
+	return assignees
+}
@@ -0,0 +1,114 @@
+package github
+
+import (
+	"github.com/eveldcorp/devrel-github/database"
+	"github.com/shurcooL/githubv4"
+)
+
+// Query the stargazers of a repository with the time they starred it.
+func (g *GithubImpl) queryStargazers(owner string, repository string) ([]database.Stargazer, error) {
+	var query struct {
+		Repository struct {
+			Stargazers struct {
+				Edges []struct {
+					StarredAt githubv4.DateTime
+					Node      GithubActor
+				}
+				PageInfo   PageInfo
+				TotalCount int
+			} `graphql:"stargazers(first: 100, after: $cursor, orderBy: { field: STARRED_AT, direction: ASC })"`
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+		RateLimit RateLimit
+	}
+
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+		"cursor":     (*githubv4.String)(nil),
+	}
+
+	page := 0
+	stargazers := []database.Stargazer{}
+
+	for {
+		g.logger.Debug("Querying stargazers", "owner", owner, "repository", repository, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
+		if err != nil {
+			return stargazers, err
+		}
+
+		// Process stargazers
+		for _, e := range query.Repository.Stargazers.Edges {
+			stargazers = append(stargazers, database.Stargazer{
+				Host:       g.host,
+				Owner:      owner,
+				Repository: repository,
+				Login:      e.Node.Login,
+				StarredAt:  e.StarredAt.Time,
+			})
+		}
+
+		if !query.Repository.Stargazers.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(query.Repository.Stargazers.PageInfo.EndCursor)
+		page++
+	}
+
+	return stargazers, nil
+}
+
+// Query the forks of a repository with the time they were created.
+func (g *GithubImpl) queryForks(owner string, repository string) ([]database.Fork, error) {
+	var query struct {
+		Repository struct {
+			Forks struct {
+				Nodes []struct {
+					NameWithOwner string
+					CreatedAt     githubv4.DateTime
+				}
+				PageInfo   PageInfo
+				TotalCount int
+			} `graphql:"forks(first: 100, after: $cursor, orderBy: { field: CREATED_AT, direction: ASC })"`
+		} `graphql:"repository(name: $repository, owner: $owner)"`
+		RateLimit RateLimit
+	}
+
+	variables := map[string]interface{}{
+		"owner":      githubv4.String(owner),
+		"repository": githubv4.String(repository),
+		"cursor":     (*githubv4.String)(nil),
+	}
+
+	page := 0
+	forks := []database.Fork{}
+
+	for {
+		g.logger.Debug("Querying forks", "owner", owner, "repository", repository, "page", page)
+		err := g.v4.Query(g.ctx, &query, variables)
+		if err != nil {
+			return forks, err
+		}
+
+		// Process forks
+		for _, f := range query.Repository.Forks.Nodes {
+			forks = append(forks, database.Fork{
+				Host:       g.host,
+				Owner:      owner,
+				Repository: repository,
+				Fork:       f.NameWithOwner,
+				CreatedAt:  f.CreatedAt.Time,
+			})
+		}
+
+		if !query.Repository.Forks.PageInfo.HasNextPage {
+			break
+		}
+
+		variables["cursor"] = githubv4.String(query.Repository.Forks.PageInfo.EndCursor)
+		page++
+	}
+
+	return forks, nil
+}
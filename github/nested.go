@@ -0,0 +1,391 @@
+package github
+
+import (
+	"github.com/shurcooL/githubv4"
+)
+
+// Query the following pages of a nested connection of the node with the id
+// node, starting after cursor. query is queried with the $node and $cursor
+// variables for every page, and next is called after every page to collect
+// the nodes of the page and return its page info.
+func (g *GithubImpl) queryNested(connection string, node string, cursor string, query interface{}, next func() PageInfo) error {
+	return g.queryNestedWith(connection, node, cursor, nil, query, next)
+}
+
+// Query the following pages of a nested connection like queryNested, with
+// additional variables of query, such as the item types of a timeline.
+func (g *GithubImpl) queryNestedWith(connection string, node string, cursor string, extra map[string]interface{}, query interface{}, next func() PageInfo) error {
+	variables := map[string]interface{}{
+		"node":   githubv4.ID(node),
+		"cursor": githubv4.String(cursor),
+	}
+	for k, v := range extra {
+		variables[k] = v
+	}
+
+	for page := 1; ; page++ {
+		g.logger.Debug("Querying nested connection", "connection", connection, "node", node, "page", page)
+		err := g.v4.Query(g.ctx, query, variables)
+		if err != nil {
+			return err
+		}
+
+		pageInfo := next()
+		if !pageInfo.HasNextPage {
+			return nil
+		}
+
+		variables["cursor"] = githubv4.String(pageInfo.EndCursor)
+	}
+}
+
+// Query the labels of an issue, pullrequest or discussion after cursor.
+func (g *GithubImpl) queryLabels(node string, cursor string) ([]GithubLabel, error) {
+	var query struct {
+		Node struct {
+			Labelable struct {
+				Labels GithubLabels `graphql:"labels(first: 100, after: $cursor)"`
+			} `graphql:"... on Labelable"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	labels := []GithubLabel{}
+	err := g.queryNested("labels", node, cursor, &query, func() PageInfo {
+		labels = append(labels, query.Node.Labelable.Labels.Nodes...)
+		return query.Node.Labelable.Labels.PageInfo
+	})
+
+	return labels, err
+}
+
+// Query the assignees of an issue or pullrequest after cursor.
+func (g *GithubImpl) queryAssignees(node string, cursor string) ([]GithubActor, error) {
+	var query struct {
+		Node struct {
+			Assignable struct {
+				Assignees GithubAssignees `graphql:"assignees(first: 100, after: $cursor)"`
+			} `graphql:"... on Assignable"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	assignees := []GithubActor{}
+	err := g.queryNested("assignees", node, cursor, &query, func() PageInfo {
+		assignees = append(assignees, query.Node.Assignable.Assignees.Nodes...)
+		return query.Node.Assignable.Assignees.PageInfo
+	})
+
+	return assignees, err
+}
+
+// Query the files of a pullrequest after cursor.
+func (g *GithubImpl) queryFiles(node string, cursor string) ([]GithubFile, error) {
+	var query struct {
+		Node struct {
+			PullRequest struct {
+				Files struct {
+					Nodes    []GithubFile
+					PageInfo PageInfo
+				} `graphql:"files(first: 100, after: $cursor)"`
+			} `graphql:"... on PullRequest"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	files := []GithubFile{}
+	err := g.queryNested("files", node, cursor, &query, func() PageInfo {
+		files = append(files, query.Node.PullRequest.Files.Nodes...)
+		return query.Node.PullRequest.Files.PageInfo
+	})
+
+	return files, err
+}
+
+// Query the reviews of a pullrequest after cursor.
+func (g *GithubImpl) queryReviews(node string, cursor string) ([]GithubReview, error) {
+	var query struct {
+		Node struct {
+			PullRequest struct {
+				Reviews struct {
+					Nodes    []GithubReview
+					PageInfo PageInfo
+				} `graphql:"reviews(first: 100, after: $cursor)"`
+			} `graphql:"... on PullRequest"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	reviews := []GithubReview{}
+	err := g.queryNested("reviews", node, cursor, &query, func() PageInfo {
+		reviews = append(reviews, query.Node.PullRequest.Reviews.Nodes...)
+		return query.Node.PullRequest.Reviews.PageInfo
+	})
+
+	return reviews, err
+}
+
+// Query the commits of a pullrequest after cursor.
+func (g *GithubImpl) queryPullrequestCommits(node string, cursor string) ([]GithubPullrequestCommit, error) {
+	var query struct {
+		Node struct {
+			PullRequest struct {
+				Commits struct {
+					Nodes    []GithubPullrequestCommit
+					PageInfo PageInfo
+				} `graphql:"commits(first: 100, after: $cursor)"`
+			} `graphql:"... on PullRequest"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	commits := []GithubPullrequestCommit{}
+	err := g.queryNested("commits", node, cursor, &query, func() PageInfo {
+		commits = append(commits, query.Node.PullRequest.Commits.Nodes...)
+		return query.Node.PullRequest.Commits.PageInfo
+	})
+
+	return commits, err
+}
+
+// Query the check runs and status contexts of a status check rollup after
+// cursor.
+func (g *GithubImpl) queryCheckContexts(node string, cursor string) ([]GithubCheckContext, error) {
+	var query struct {
+		Node struct {
+			StatusCheckRollup struct {
+				Contexts struct {
+					Nodes    []GithubCheckContext
+					PageInfo PageInfo
+				} `graphql:"contexts(first: 100, after: $cursor)"`
+			} `graphql:"... on StatusCheckRollup"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	contexts := []GithubCheckContext{}
+	err := g.queryNested("check contexts", node, cursor, &query, func() PageInfo {
+		contexts = append(contexts, query.Node.StatusCheckRollup.Contexts.Nodes...)
+		return query.Node.StatusCheckRollup.Contexts.PageInfo
+	})
+
+	return contexts, err
+}
+
+// Query the comments of a review thread after cursor.
+func (g *GithubImpl) queryReviewThreadComments(node string, cursor string) ([]GithubReviewComment, error) {
+	var query struct {
+		Node struct {
+			PullRequestReviewThread struct {
+				Comments struct {
+					Nodes    []GithubReviewComment
+					PageInfo PageInfo
+				} `graphql:"comments(first: 100, after: $cursor)"`
+			} `graphql:"... on PullRequestReviewThread"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	comments := []GithubReviewComment{}
+	err := g.queryNested("review thread comments", node, cursor, &query, func() PageInfo {
+		comments = append(comments, query.Node.PullRequestReviewThread.Comments.Nodes...)
+		return query.Node.PullRequestReviewThread.Comments.PageInfo
+	})
+
+	return comments, err
+}
+
+// Query the assets of a release after cursor.
+func (g *GithubImpl) queryReleaseAssets(node string, cursor string) ([]GithubReleaseAsset, error) {
+	var query struct {
+		Node struct {
+			Release struct {
+				ReleaseAssets struct {
+					Nodes    []GithubReleaseAsset
+					PageInfo PageInfo
+				} `graphql:"releaseAssets(first: 100, after: $cursor)"`
+			} `graphql:"... on Release"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	assets := []GithubReleaseAsset{}
+	err := g.queryNested("release assets", node, cursor, &query, func() PageInfo {
+		assets = append(assets, query.Node.Release.ReleaseAssets.Nodes...)
+		return query.Node.Release.ReleaseAssets.PageInfo
+	})
+
+	return assets, err
+}
+
+// Query the replies to a discussion comment after cursor.
+func (g *GithubImpl) queryReplies(node string, cursor string) ([]GithubDiscussionReply, error) {
+	var query struct {
+		Node struct {
+			DiscussionComment struct {
+				Replies struct {
+					Nodes    []GithubDiscussionReply
+					PageInfo PageInfo
+				} `graphql:"replies(first: 100, after: $cursor)"`
+			} `graphql:"... on DiscussionComment"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	replies := []GithubDiscussionReply{}
+	err := g.queryNested("replies", node, cursor, &query, func() PageInfo {
+		replies = append(replies, query.Node.DiscussionComment.Replies.Nodes...)
+		return query.Node.DiscussionComment.Replies.PageInfo
+	})
+
+	return replies, err
+}
+
+// Complete the labels, assignees and timeline of an issue with the following
+// pages.
+func (g *GithubImpl) completeIssue(i *GithubIssue) error {
+	if i.Labels.PageInfo.HasNextPage {
+		labels, err := g.queryLabels(i.ID, i.Labels.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		i.Labels.Nodes = append(i.Labels.Nodes, labels...)
+	}
+
+	if i.Assignees.PageInfo.HasNextPage {
+		assignees, err := g.queryAssignees(i.ID, i.Assignees.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		i.Assignees.Nodes = append(i.Assignees.Nodes, assignees...)
+	}
+
+	if i.Timeline.PageInfo.HasNextPage {
+		items, err := g.queryIssueTimeline(i.ID, i.Timeline.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		i.Timeline.Nodes = append(i.Timeline.Nodes, items...)
+	}
+
+	return nil
+}
+
+// Complete the labels, assignees, reviews, files, timeline, review threads,
+// commits and checks of a pullrequest with the following pages.
+func (g *GithubImpl) completePullrequest(p *GithubPullrequest) error {
+	if p.Labels.PageInfo.HasNextPage {
+		labels, err := g.queryLabels(p.ID, p.Labels.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		p.Labels.Nodes = append(p.Labels.Nodes, labels...)
+	}
+
+	if p.Assignees.PageInfo.HasNextPage {
+		assignees, err := g.queryAssignees(p.ID, p.Assignees.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		p.Assignees.Nodes = append(p.Assignees.Nodes, assignees...)
+	}
+
+	if p.Reviews.PageInfo.HasNextPage {
+		reviews, err := g.queryReviews(p.ID, p.Reviews.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		p.Reviews.Nodes = append(p.Reviews.Nodes, reviews...)
+	}
+
+	if p.Files.PageInfo.HasNextPage {
+		files, err := g.queryFiles(p.ID, p.Files.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		p.Files.Nodes = append(p.Files.Nodes, files...)
+	}
+
+	if p.Timeline.PageInfo.HasNextPage {
+		items, err := g.queryPullrequestTimeline(p.ID, p.Timeline.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		p.Timeline.Nodes = append(p.Timeline.Nodes, items...)
+	}
+
+	if p.ReviewThreads.PageInfo.HasNextPage || hasMoreReviewComments(p.ReviewThreads.Nodes) {
+		threads, err := g.completeReviewThreads(p.ID, p.ReviewThreads.Nodes, p.ReviewThreads.PageInfo)
+		if err != nil {
+			return err
+		}
+		p.ReviewThreads.Nodes = threads
+	}
+
+	if p.Commits.PageInfo.HasNextPage {
+		commits, err := g.queryPullrequestCommits(p.ID, p.Commits.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		p.Commits.Nodes = append(p.Commits.Nodes, commits...)
+	}
+
+	for i, c := range p.HeadCommit.Nodes {
+		rollup := c.Commit.StatusCheckRollup
+		if !rollup.Contexts.PageInfo.HasNextPage {
+			continue
+		}
+
+		contexts, err := g.queryCheckContexts(rollup.ID, rollup.Contexts.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		p.HeadCommit.Nodes[i].Commit.StatusCheckRollup.Contexts.Nodes = append(rollup.Contexts.Nodes, contexts...)
+	}
+
+	return nil
+}
+
+// Complete the labels of a discussion and the replies to its comments with the
+// following pages.
+func (g *GithubImpl) completeDiscussion(d *GithubDiscussion) error {
+	if d.Labels.PageInfo.HasNextPage {
+		labels, err := g.queryLabels(d.ID, d.Labels.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		d.Labels.Nodes = append(d.Labels.Nodes, labels...)
+	}
+
+	return g.completeReplies(d.Comments.Nodes)
+}
+
+// Complete the replies to discussion comments with the following pages.
+func (g *GithubImpl) completeReplies(comments []GithubDiscussionComment) error {
+	for i, c := range comments {
+		if !c.Replies.PageInfo.HasNextPage {
+			continue
+		}
+
+		replies, err := g.queryReplies(c.ID, c.Replies.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		comments[i].Replies.Nodes = append(comments[i].Replies.Nodes, replies...)
+	}
+
+	return nil
+}
+
+// Complete the assets of a release with the following pages.
+func (g *GithubImpl) completeRelease(r *GithubRelease) error {
+	if r.ReleaseAssets.PageInfo.HasNextPage {
+		assets, err := g.queryReleaseAssets(r.ID, r.ReleaseAssets.PageInfo.EndCursor)
+		if err != nil {
+			return err
+		}
+		r.ReleaseAssets.Nodes = append(r.ReleaseAssets.Nodes, assets...)
+	}
+
+	return nil
+}
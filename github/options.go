@@ -0,0 +1,171 @@
+package github
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// The host of the public GitHub.
+const publicHost = "github.com"
+
+// Options holds the GitHub instance to query and the credentials used to query
+// it. Every token and the GitHub App installation are added to a pool that the
+// client rotates through.
+type Options struct {
+	// The context of every query, cancelling it stops the running queries.
+	// Defaults to context.Background().
+	Context context.Context
+
+	Tokens []string
+
+	// GitHub App authentication.
+	AppID          int64
+	InstallationID int64
+	PrivateKey     []byte
+
+	// The URL of a GitHub Enterprise Server, and the API URLs when they differ
+	// from the defaults of the server.
+	URL        string
+	RESTURL    string
+	GraphQLURL string
+
+	// A PEM file of additional CA certificates, and the proxy to use instead
+	// of the proxy of the environment.
+	CABundle string
+	Proxy    string
+}
+
+type endpoints struct {
+	host    string
+	rest    string
+	graphql string
+	upload  string
+}
+
+// Resolve the API endpoints, which default to the public GitHub or to the
+// endpoints of the GitHub Enterprise Server at URL. Without URL the host is
+// taken from the API URLs, which have to agree on it.
+func (o Options) endpoints() (endpoints, error) {
+	e := endpoints{
+		host:    publicHost,
+		rest:    "https://api.github.com/",
+		graphql: "https://api.github.com/graphql",
+		upload:  "https://uploads.github.com/",
+	}
+
+	if o.URL != "" {
+		u, err := url.Parse(o.URL)
+		if err != nil || u.Host == "" {
+			return e, fmt.Errorf("invalid GitHub URL %q", o.URL)
+		}
+
+		base := strings.TrimSuffix(u.String(), "/")
+
+		e.host = u.Hostname()
+		e.rest = base + "/api/v3/"
+		e.graphql = base + "/api/graphql"
+		e.upload = base + "/api/uploads/"
+	}
+
+	hosts := []string{}
+
+	if o.RESTURL != "" {
+		host, err := apiHost(o.RESTURL)
+		if err != nil {
+			return e, err
+		}
+		hosts = append(hosts, host)
+
+		e.rest = strings.TrimSuffix(o.RESTURL, "/") + "/"
+		if strings.HasSuffix(e.rest, "/api/v3/") {
+			e.upload = strings.TrimSuffix(e.rest, "/api/v3/") + "/api/uploads/"
+		}
+	}
+
+	if o.GraphQLURL != "" {
+		host, err := apiHost(o.GraphQLURL)
+		if err != nil {
+			return e, err
+		}
+		hosts = append(hosts, host)
+
+		e.graphql = o.GraphQLURL
+	}
+
+	if o.URL == "" && len(hosts) > 0 {
+		if len(hosts) == 2 && hosts[0] != hosts[1] {
+			return e, fmt.Errorf("the REST and GraphQL URLs are on different hosts, set the GitHub URL")
+		}
+
+		e.host = hosts[0]
+	}
+
+	return e, nil
+}
+
+// The host of the GitHub instance of an API URL, the API of the public GitHub
+// is on its own subdomain.
+func apiHost(apiURL string) (string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid GitHub API URL %q", apiURL)
+	}
+
+	host := u.Hostname()
+	if host == "api."+publicHost {
+		return publicHost, nil
+	}
+
+	return host, nil
+}
+
+// Configure the CA bundle and proxy of the transport.
+func (o Options) transport(base http.RoundTripper) (http.RoundTripper, error) {
+	if o.CABundle == "" && o.Proxy == "" {
+		return base, nil
+	}
+
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("could not configure the transport of the GitHub client")
+	}
+	transport = transport.Clone()
+
+	if o.CABundle != "" {
+		pem, err := os.ReadFile(o.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA bundle: %v", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CABundle)
+		}
+
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if o.Proxy != "" {
+		proxy, err := url.Parse(o.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q: %v", o.Proxy, err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return transport, nil
+}
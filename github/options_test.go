@@ -0,0 +1,108 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestEndpoints(t *testing.T) {
+	cases := []struct {
+		name    string
+		options Options
+		want    endpoints
+		wantErr bool
+	}{
+		{
+			name:    "public GitHub",
+			options: Options{},
+			want: endpoints{
+				host:    "github.com",
+				rest:    "https://api.github.com/",
+				graphql: "https://api.github.com/graphql",
+				upload:  "https://uploads.github.com/",
+			},
+		},
+		{
+			name:    "enterprise server",
+			options: Options{URL: "https://github.example.com/"},
+			want: endpoints{
+				host:    "github.example.com",
+				rest:    "https://github.example.com/api/v3/",
+				graphql: "https://github.example.com/api/graphql",
+				upload:  "https://github.example.com/api/uploads/",
+			},
+		},
+		{
+			name:    "enterprise server with API URLs",
+			options: Options{URL: "https://github.example.com", RESTURL: "https://api.example.com/v3", GraphQLURL: "https://api.example.com/graphql"},
+			want: endpoints{
+				host:    "github.example.com",
+				rest:    "https://api.example.com/v3/",
+				graphql: "https://api.example.com/graphql",
+				upload:  "https://github.example.com/api/uploads/",
+			},
+		},
+		{
+			name:    "only API URLs",
+			options: Options{RESTURL: "https://github.example.com/api/v3", GraphQLURL: "https://github.example.com/api/graphql"},
+			want: endpoints{
+				host:    "github.example.com",
+				rest:    "https://github.example.com/api/v3/",
+				graphql: "https://github.example.com/api/graphql",
+				upload:  "https://github.example.com/api/uploads/",
+			},
+		},
+		{
+			name:    "only GraphQL URL",
+			options: Options{GraphQLURL: "https://github.example.com/api/graphql"},
+			want: endpoints{
+				host:    "github.example.com",
+				rest:    "https://api.github.com/",
+				graphql: "https://github.example.com/api/graphql",
+				upload:  "https://uploads.github.com/",
+			},
+		},
+		{
+			name:    "public API URL",
+			options: Options{RESTURL: "https://api.github.com/"},
+			want: endpoints{
+				host:    "github.com",
+				rest:    "https://api.github.com/",
+				graphql: "https://api.github.com/graphql",
+				upload:  "https://uploads.github.com/",
+			},
+		},
+		{
+			name:    "API URLs on different hosts",
+			options: Options{RESTURL: "https://rest.example.com/", GraphQLURL: "https://graphql.example.com/graphql"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid URL",
+			options: Options{URL: "github.example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid API URL",
+			options: Options{RESTURL: "/api/v3"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.options.endpoints()
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// The margin added to a reset time to account for clock skew.
+const resetMargin = time.Second
+
+// The wait before retrying a secondary rate limit without a Retry-After header.
+const secondaryWait = time.Minute
+
+// Governor tracks the rate limits of the GitHub API per token and resource,
+// and delays requests until the limit has been reset instead of failing them.
+// It is shared by the GraphQL and REST clients.
+type Governor struct {
+	next   http.RoundTripper
+	logger hclog.Logger
+
+	lock   sync.Mutex
+	limits map[string]*Limit
+	blocks map[string]time.Time
+}
+
+// Limit is the last known state of a rate limit.
+type Limit struct {
+	Token     string
+	Resource  string
+	Limit     int
+	Remaining int
+	Used      int
+	ResetAt   time.Time
+
+	// Until when the token is blocked by a secondary rate limit.
+	BlockedUntil time.Time
+}
+
+// Available returns when the rate limit allows the next request.
+func (l Limit) Available() time.Time {
+	available := l.BlockedUntil
+	if l.Remaining <= 0 && l.ResetAt.Add(resetMargin).After(available) {
+		available = l.ResetAt.Add(resetMargin)
+	}
+
+	return available
+}
+
+// The remaining requests at now, a limit that has been reset is full again.
+func (l Limit) left(now time.Time) int {
+	if !l.ResetAt.IsZero() && l.ResetAt.Before(now) {
+		return l.Limit
+	}
+
+	return l.Remaining
+}
+
+func NewGovernor(next http.RoundTripper, logger hclog.Logger) *Governor {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Governor{
+		next:   next,
+		logger: logger.Named("RateLimit"),
+		limits: map[string]*Limit{},
+		blocks: map[string]time.Time{},
+	}
+}
+
+// RoundTrip waits until the rate limit of the token and resource of the
+// request allows it to be sent, sends it, and records the rate limit of the
+// response.
+func (g *Governor) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := fingerprint(req.Header.Get("Authorization"))
+
+	err := g.wait(req.Context(), token, resource(req))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	g.record(token, resp)
+
+	return resp, nil
+}
+
+// Limit returns the last known state of the rate limit of a token and
+// resource.
+func (g *Governor) Limit(token string, resource string) (Limit, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	blocked, isBlocked := g.blocks[token]
+
+	l, ok := g.limits[key(token, resource)]
+	if !ok {
+		return Limit{Token: token, Resource: resource, BlockedUntil: blocked}, isBlocked
+	}
+
+	limit := *l
+	limit.BlockedUntil = blocked
+
+	return limit, true
+}
+
+// Limits returns the last known state of every rate limit.
+func (g *Governor) Limits() []Limit {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	limits := []Limit{}
+	for _, l := range g.limits {
+		limits = append(limits, *l)
+	}
+
+	return limits
+}
+
+// CheckRetry retries requests that were rejected by a primary or secondary
+// rate limit, the wait itself happens in RoundTrip before the next attempt.
+func (g *Governor) CheckRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() == nil && err == nil && limited(resp) {
+		return true, nil
+	}
+
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
+
+// Backoff does not back off from rate limited requests, as RoundTrip waits
+// until the rate limit has been reset.
+func (g *Governor) Backoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if resp != nil && limited(resp) {
+		return 0
+	}
+
+	return retryablehttp.DefaultBackoff(min, max, attemptNum, resp)
+}
+
+// Wait until neither a secondary rate limit of the token nor the primary rate
+// limit of the token and resource is in effect.
+func (g *Governor) wait(ctx context.Context, token string, resource string) error {
+	for {
+		limit, _ := g.Limit(token, resource)
+
+		until := limit.Available()
+		reason := "primary"
+		if until.Equal(limit.BlockedUntil) {
+			reason = "secondary"
+		}
+
+		d := time.Until(until)
+		if d <= 0 {
+			return nil
+		}
+
+		g.logger.Info("Waiting for rate limit reset", "token", token, "resource", resource, "limit", reason, "until", until.Format(time.RFC3339), "wait", d.Round(time.Second))
+
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Record the rate limit and secondary rate limit of a response.
+func (g *Governor) record(token string, resp *http.Response) {
+	header := resp.Header
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if header.Get("X-RateLimit-Remaining") != "" {
+		l := &Limit{
+			Token:    token,
+			Resource: header.Get("X-RateLimit-Resource"),
+		}
+		if l.Resource == "" {
+			l.Resource = resource(resp.Request)
+		}
+
+		l.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+		l.Remaining, _ = strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+		l.Used, _ = strconv.Atoi(header.Get("X-RateLimit-Used"))
+
+		reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+		if err == nil {
+			l.ResetAt = time.Unix(reset, 0)
+		}
+
+		g.limits[key(token, l.Resource)] = l
+
+		g.logger.Debug("Rate limit", "token", token, "resource", l.Resource, "limit", l.Limit, "remaining", l.Remaining, "used", l.Used, "reset", l.ResetAt.Format(time.RFC3339))
+
+		if l.Remaining <= 0 {
+			// go-github refuses to send requests on its own while the limit is
+			// exhausted, leave the waiting to the governor instead.
+			header.Del("X-RateLimit-Reset")
+		}
+	}
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		wait := secondaryWait
+
+		after := header.Get("Retry-After")
+		if after != "" {
+			seconds, err := strconv.Atoi(after)
+			if err == nil {
+				wait = time.Duration(seconds) * time.Second
+			}
+		} else if header.Get("X-RateLimit-Remaining") == "0" {
+			// A primary rate limit, which is already recorded.
+			return
+		} else if !secondary(resp) {
+			return
+		}
+
+		g.blocks[token] = time.Now().Add(wait)
+
+		g.logger.Warn("Secondary rate limit", "token", token, "retry-after", wait)
+	}
+}
+
+// Check whether a response was rejected by a rate limit.
+func limited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" || secondary(resp)
+	case http.StatusOK:
+		// GraphQL reports an exhausted rate limit as an error in the body.
+		return resource(resp.Request) == "graphql" && resp.Header.Get("X-RateLimit-Remaining") == "0" && contains(resp, "RATE_LIMITED")
+	}
+
+	return false
+}
+
+// Check whether a forbidden response is a secondary rate limit.
+func secondary(resp *http.Response) bool {
+	return contains(resp, "secondary rate limit")
+}
+
+// Check whether the body of a response contains s, leaving the body intact.
+func contains(resp *http.Response, s string) bool {
+	if resp.Body == nil {
+		return false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	return bytes.Contains(body, []byte(s))
+}
+
+// The rate limit resource a request counts against.
+func resource(req *http.Request) string {
+	if req == nil {
+		return "core"
+	}
+
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		return "graphql"
+	case strings.Contains(req.URL.Path, "/search/"):
+		return "search"
+	}
+
+	return "core"
+}
+
+// Identify a token in logs without revealing it.
+func fingerprint(authorization string) string {
+	if authorization == "" {
+		return "anonymous"
+	}
+
+	sum := sha256.Sum256([]byte(authorization))
+	return hex.EncodeToString(sum[:4])
+}
+
+func key(token string, resource string) string {
+	return token + "/" + resource
+}
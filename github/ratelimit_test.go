@@ -0,0 +1,226 @@
+package github
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// A response to a request of url with a status, headers and body.
+func response(url string, status int, headers map[string]string, body string) *http.Response {
+	req, _ := http.NewRequest(http.MethodPost, url, nil)
+
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+
+	return resp
+}
+
+func TestLimited(t *testing.T) {
+	cases := []struct {
+		name    string
+		url     string
+		status  int
+		headers map[string]string
+		body    string
+		want    bool
+	}{
+		{
+			name:    "ok",
+			url:     "https://api.github.com/repos/o/r",
+			status:  http.StatusOK,
+			headers: map[string]string{"X-RateLimit-Remaining": "10"},
+			body:    "{}",
+			want:    false,
+		},
+		{
+			name:   "too many requests",
+			url:    "https://api.github.com/repos/o/r",
+			status: http.StatusTooManyRequests,
+			want:   true,
+		},
+		{
+			name:    "primary rate limit",
+			url:     "https://api.github.com/repos/o/r",
+			status:  http.StatusForbidden,
+			headers: map[string]string{"X-RateLimit-Remaining": "0"},
+			want:    true,
+		},
+		{
+			name:    "retry after",
+			url:     "https://api.github.com/repos/o/r",
+			status:  http.StatusForbidden,
+			headers: map[string]string{"Retry-After": "30"},
+			want:    true,
+		},
+		{
+			name:   "secondary rate limit",
+			url:    "https://api.github.com/repos/o/r",
+			status: http.StatusForbidden,
+			body:   `{"message":"You have exceeded a secondary rate limit."}`,
+			want:   true,
+		},
+		{
+			name:    "forbidden",
+			url:     "https://api.github.com/repos/o/r",
+			status:  http.StatusForbidden,
+			headers: map[string]string{"X-RateLimit-Remaining": "10"},
+			body:    `{"message":"Resource not accessible"}`,
+			want:    false,
+		},
+		{
+			name:    "graphql rate limited",
+			url:     "https://api.github.com/graphql",
+			status:  http.StatusOK,
+			headers: map[string]string{"X-RateLimit-Remaining": "0"},
+			body:    `{"errors":[{"type":"RATE_LIMITED"}]}`,
+			want:    true,
+		},
+		{
+			name:    "graphql last request",
+			url:     "https://api.github.com/graphql",
+			status:  http.StatusOK,
+			headers: map[string]string{"X-RateLimit-Remaining": "0"},
+			body:    `{"data":{}}`,
+			want:    false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp := response(c.url, c.status, c.headers, c.body)
+
+			got := limited(resp)
+			if got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+
+			// The body is left intact for the client.
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != c.body {
+				t.Errorf("got body %q, want %q", body, c.body)
+			}
+		})
+	}
+}
+
+func TestLimitAvailable(t *testing.T) {
+	now := time.Now()
+	reset := now.Add(time.Hour)
+
+	cases := []struct {
+		name  string
+		limit Limit
+		want  time.Time
+	}{
+		{
+			name:  "remaining",
+			limit: Limit{Remaining: 10, ResetAt: reset},
+			want:  time.Time{},
+		},
+		{
+			name:  "exhausted",
+			limit: Limit{Remaining: 0, ResetAt: reset},
+			want:  reset.Add(resetMargin),
+		},
+		{
+			name:  "blocked",
+			limit: Limit{Remaining: 10, ResetAt: reset, BlockedUntil: now.Add(time.Minute)},
+			want:  now.Add(time.Minute),
+		},
+		{
+			name:  "exhausted and blocked for longer",
+			limit: Limit{Remaining: 0, ResetAt: reset, BlockedUntil: reset.Add(time.Hour)},
+			want:  reset.Add(time.Hour),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.limit.Available()
+			if !got.Equal(c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestGovernorRecord(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp := response(req.URL.String(), http.StatusOK, map[string]string{
+			"X-RateLimit-Limit":     "5000",
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Used":      "5000",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+			"X-RateLimit-Resource":  "core",
+		}, "{}")
+		resp.Request = req
+		return resp, nil
+	})
+
+	g := NewGovernor(next, hclog.NewNullLogger())
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r", nil)
+	req.Header.Set("Authorization", "token secret")
+
+	resp, err := g.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := fingerprint("token secret")
+	limit, ok := g.Limit(token, "core")
+	if !ok {
+		t.Fatalf("no limit recorded")
+	}
+
+	if limit.Limit != 5000 || limit.Remaining != 0 || !limit.ResetAt.Equal(reset) {
+		t.Errorf("unexpected limit %+v", limit)
+	}
+
+	// go-github must not refuse requests on its own.
+	if resp.Header.Get("X-RateLimit-Reset") != "" {
+		t.Errorf("reset header was not removed")
+	}
+
+	if _, ok := g.Limit(token, "graphql"); ok {
+		t.Errorf("limit recorded for another resource")
+	}
+}
+
+func TestResource(t *testing.T) {
+	cases := map[string]string{
+		"https://api.github.com/graphql":                "graphql",
+		"https://github.example.com/api/graphql":        "graphql",
+		"https://api.github.com/search/issues?q=x":      "search",
+		"https://api.github.com/repos/o/r/traffic/pops": "core",
+	}
+
+	for url, want := range cases {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		got := resource(req)
+		if got != want {
+			t.Errorf("%s: got %q, want %q", url, got, want)
+		}
+	}
+}
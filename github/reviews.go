@@ -0,0 +1,110 @@
+package github
+
+import (
+	"github.com/eveldcorp/devrel-github/database"
+)
+
+// Complete the review threads of the first page of a pullrequest with the
+// threads of the following pages and the comments of every thread.
+func (g *GithubImpl) completeReviewThreads(node string, threads []GithubReviewThread, pageInfo PageInfo) ([]GithubReviewThread, error) {
+	var query struct {
+		Node struct {
+			PullRequest struct {
+				ReviewThreads struct {
+					Nodes    []GithubReviewThread
+					PageInfo PageInfo
+				} `graphql:"reviewThreads(first: 25, after: $cursor)"`
+			} `graphql:"... on PullRequest"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	threads = append([]GithubReviewThread{}, threads...)
+
+	if pageInfo.HasNextPage {
+		err := g.queryNested("review threads", node, pageInfo.EndCursor, &query, func() PageInfo {
+			threads = append(threads, query.Node.PullRequest.ReviewThreads.Nodes...)
+			return query.Node.PullRequest.ReviewThreads.PageInfo
+		})
+		if err != nil {
+			return threads, err
+		}
+	}
+
+	// Query additional comments
+	for i, t := range threads {
+		if !t.Comments.PageInfo.HasNextPage {
+			continue
+		}
+
+		comments, err := g.queryReviewThreadComments(t.ID, t.Comments.PageInfo.EndCursor)
+		if err != nil {
+			return threads, err
+		}
+
+		threads[i].Comments.Nodes = append(threads[i].Comments.Nodes, comments...)
+	}
+
+	return threads, nil
+}
+
+// Check whether any review thread has more comments than were queried.
+func hasMoreReviewComments(threads []GithubReviewThread) bool {
+	for _, t := range threads {
+		if t.Comments.PageInfo.HasNextPage {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Map queried review threads and their comments.
+func mapReviewThreads(nodes []GithubReviewThread) []database.PullrequestReviewThread {
+	threads := []database.PullrequestReviewThread{}
+
+	for _, t := range nodes {
+		thread := database.PullrequestReviewThread{
+			ID:                t.ID,
+			Path:              t.Path,
+			Line:              t.Line,
+			OriginalLine:      t.OriginalLine,
+			StartLine:         t.StartLine,
+			OriginalStartLine: t.OriginalStartLine,
+			DiffSide:          t.DiffSide,
+			IsResolved:        t.IsResolved,
+			IsOutdated:        t.IsOutdated,
+			IsCollapsed:       t.IsCollapsed,
+			ResolvedBy:        t.ResolvedBy.Login,
+			Comments:          []database.PullrequestReviewComment{},
+		}
+
+		// Comments
+		for _, c := range t.Comments.Nodes {
+			comment := database.PullrequestReviewComment{
+				ID:                c.ID,
+				Review:            c.PullRequestReview.ID,
+				ReplyTo:           c.ReplyTo.ID,
+				Author:            c.Author.Login,
+				AuthorType:        c.Author.Typename,
+				IsBot:             c.Author.Typename == "Bot",
+				AuthorAssociation: c.AuthorAssociation,
+				Body:              c.Body,
+				DiffHunk:          c.DiffHunk,
+				Path:              c.Path,
+				Line:              c.Line,
+				OriginalLine:      c.OriginalLine,
+				Outdated:          c.Outdated,
+				CreatedAt:         c.CreatedAt,
+				PublishedAt:       c.PublishedAt,
+				UpdatedAt:         c.UpdatedAt,
+				LastEditedAt:      c.LastEditedAt,
+			}
+			thread.Comments = append(thread.Comments, comment)
+		}
+
+		threads = append(threads, thread)
+	}
+
+	return threads
+}
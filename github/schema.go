@@ -27,13 +27,31 @@ type GithubReaction struct {
 }
 
 type GithubLabel struct {
+	ID    string
 	Name  string
 	Color string
 }
 
+type GithubLabels struct {
+	Nodes      []GithubLabel
+	PageInfo   PageInfo
+	TotalCount int
+}
+
+type GithubAssignees struct {
+	Nodes      []GithubActor
+	PageInfo   PageInfo
+	TotalCount int
+}
+
+type GithubMilestoneRef struct {
+	ID string
+}
+
 type GithubAuthor struct {
-	Login string
-	User  struct {
+	Typename string `graphql:"__typename"`
+	Login    string
+	User     struct {
 		Name  string
 		Email string
 	} `graphql:"... on User"`
@@ -43,6 +61,13 @@ type GithubActor struct {
 	Login string
 }
 
+type GithubRepository struct {
+	Name       string
+	Owner      GithubActor
+	IsArchived bool
+	IsFork     bool
+}
+
 type GithubRelease struct {
 	ID            string
 	Name          string
@@ -52,7 +77,9 @@ type GithubRelease struct {
 	IsPrerelease  bool
 	TagName       string
 	ReleaseAssets struct {
-		Nodes []GithubReleaseAsset
+		Nodes      []GithubReleaseAsset
+		PageInfo   PageInfo
+		TotalCount int
 	} `graphql:"releaseAssets(first: 100)"`
 }
 
@@ -81,21 +108,26 @@ type GithubIssue struct {
 	Comments          struct {
 		Nodes    []GithubComment
 		PageInfo PageInfo
-	} `graphql:"comments(first: 100, after: $cursor)"`
+	} `graphql:"comments(first: 100)"`
 	ReactionGroups []GithubReaction
-	Labels         struct {
-		Nodes []GithubLabel
-	} `graphql:"labels(first: 100)"`
-	TimelineItems struct {
+	Labels         GithubLabels    `graphql:"labels(first: 100)"`
+	Assignees      GithubAssignees `graphql:"assignees(first: 25)"`
+	Milestone      GithubMilestoneRef
+	TimelineItems  struct {
 		Nodes []struct {
 			IssueTimelineItemsConnection struct {
 				Actor GithubActor
 			} `graphql:"... on ClosedEvent"`
 		}
 	} `graphql:"timelineItems(itemTypes: CLOSED_EVENT, last: 1)"`
+	Timeline struct {
+		Nodes    []GithubTimelineItem
+		PageInfo PageInfo
+	} `graphql:"timeline: timelineItems(first: 100, itemTypes: $issueItemTypes)"`
 }
 
 type GithubReview struct {
+	ID                string
 	Author            GithubAuthor
 	AuthorAssociation string
 	Body              string
@@ -141,16 +173,20 @@ type GithubPullrequest struct {
 	Comments          struct {
 		Nodes    []GithubComment
 		PageInfo PageInfo
-	} `graphql:"comments(first: 100, after: $cursor)"`
+	} `graphql:"comments(first: 100)"`
 	ReactionGroups []GithubReaction
-	Labels         struct {
-		Nodes []GithubLabel
-	} `graphql:"labels(first: 100)"`
-	Reviews struct {
-		Nodes []GithubReview
+	Labels         GithubLabels    `graphql:"labels(first: 100)"`
+	Assignees      GithubAssignees `graphql:"assignees(first: 25)"`
+	Milestone      GithubMilestoneRef
+	Reviews        struct {
+		Nodes      []GithubReview
+		PageInfo   PageInfo
+		TotalCount int
 	} `graphql:"reviews(first: 100)"`
 	Files struct {
-		Nodes []GithubFile
+		Nodes      []GithubFile
+		PageInfo   PageInfo
+		TotalCount int
 	} `graphql:"files(first: 100)"`
 	TimelineItems struct {
 		Nodes []struct {
@@ -159,6 +195,281 @@ type GithubPullrequest struct {
 			} `graphql:"... on ClosedEvent"`
 		}
 	} `graphql:"timelineItems(itemTypes: CLOSED_EVENT, last: 1)"`
+	Timeline struct {
+		Nodes    []GithubPullrequestTimelineItem
+		PageInfo PageInfo
+	} `graphql:"timeline: timelineItems(first: 100, itemTypes: $pullrequestItemTypes)"`
+	ReviewThreads struct {
+		Nodes    []GithubReviewThread
+		PageInfo PageInfo
+	} `graphql:"reviewThreads(first: 25)"`
+	Commits struct {
+		Nodes      []GithubPullrequestCommit
+		PageInfo   PageInfo
+		TotalCount int
+	} `graphql:"commits(first: 100)"`
+	HeadCommit struct {
+		Nodes []GithubHeadCommit
+	} `graphql:"headCommit: commits(last: 1)"`
+}
+
+type GithubPullrequestCommit struct {
+	Commit struct {
+		Oid             string
+		MessageHeadline string
+		AuthoredDate    time.Time
+		CommittedDate   time.Time
+		Author          GithubGitActor
+	}
+}
+
+type GithubHeadCommit struct {
+	Commit struct {
+		Oid               string
+		StatusCheckRollup GithubStatusCheckRollup
+	}
+}
+
+type GithubStatusCheckRollup struct {
+	ID       string
+	State    string
+	Contexts struct {
+		Nodes      []GithubCheckContext
+		PageInfo   PageInfo
+		TotalCount int
+	} `graphql:"contexts(first: 100)"`
+}
+
+// A check run of a GitHub App or a commit status of the legacy status API.
+type GithubCheckContext struct {
+	Typename string `graphql:"__typename"`
+	CheckRun struct {
+		ID          string
+		Name        string
+		Status      string
+		Conclusion  string
+		StartedAt   time.Time
+		CompletedAt time.Time
+		DetailsURL  string
+		CheckSuite  struct {
+			App struct {
+				Name string
+			}
+			WorkflowRun struct {
+				Workflow struct {
+					Name string
+				}
+			}
+		}
+	} `graphql:"... on CheckRun"`
+	StatusContext struct {
+		ID          string
+		Context     string
+		State       string
+		Description string
+		TargetURL   string
+		CreatedAt   time.Time
+	} `graphql:"... on StatusContext"`
+}
+
+type GithubReviewComment struct {
+	ID                string
+	Author            GithubAuthor
+	AuthorAssociation string
+	Body              string
+	DiffHunk          string
+	Path              string
+	Line              int
+	OriginalLine      int
+	Outdated          bool
+	ReplyTo           struct {
+		ID string
+	}
+	PullRequestReview struct {
+		ID string
+	}
+	CreatedAt    time.Time
+	PublishedAt  time.Time
+	UpdatedAt    time.Time
+	LastEditedAt time.Time
+}
+
+type GithubReviewThread struct {
+	ID                string
+	Path              string
+	Line              int
+	OriginalLine      int
+	StartLine         int
+	OriginalStartLine int
+	DiffSide          string
+	IsResolved        bool
+	IsOutdated        bool
+	IsCollapsed       bool
+	ResolvedBy        GithubActor
+	Comments          struct {
+		Nodes    []GithubReviewComment
+		PageInfo PageInfo
+	} `graphql:"comments(first: 25)"`
+}
+
+type GithubDiscussionCategory struct {
+	ID           string
+	Name         string
+	Slug         string
+	Emoji        string
+	Description  string
+	IsAnswerable bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type GithubDiscussionReply struct {
+	ID                string
+	Author            GithubAuthor
+	AuthorAssociation string
+	Body              string
+	CreatedAt         time.Time
+	PublishedAt       time.Time
+	UpdatedAt         time.Time
+	LastEditedAt      time.Time
+	IsAnswer          bool
+	UpvoteCount       int
+	ReactionGroups    []GithubReaction
+}
+
+type GithubDiscussionComment struct {
+	GithubDiscussionReply
+	Replies struct {
+		Nodes      []GithubDiscussionReply
+		PageInfo   PageInfo
+		TotalCount int
+	} `graphql:"replies(first: 25)"`
+}
+
+type GithubDiscussion struct {
+	ID                string
+	Number            int
+	Category          GithubDiscussionCategory
+	Title             string
+	Body              string
+	Author            GithubAuthor
+	AuthorAssociation string
+	CreatedAt         time.Time
+	PublishedAt       time.Time
+	UpdatedAt         time.Time
+	LastEditedAt      time.Time
+	ClosedAt          time.Time
+	Closed            bool
+	Locked            bool
+	UpvoteCount       int
+	IsAnswered        bool
+	Answer            struct {
+		ID string
+	}
+	AnswerChosenAt time.Time
+	AnswerChosenBy GithubActor
+	ReactionGroups []GithubReaction
+	Labels         GithubLabels `graphql:"labels(first: 100)"`
+	Comments       struct {
+		Nodes    []GithubDiscussionComment
+		PageInfo PageInfo
+	} `graphql:"comments(first: 25)"`
+}
+
+type GithubTimelineEvent struct {
+	ID        string
+	Actor     GithubActor
+	CreatedAt time.Time
+}
+
+type GithubAssignee struct {
+	Actor GithubActor `graphql:"... on Actor"`
+}
+
+type GithubReviewer struct {
+	Actor GithubActor `graphql:"... on Actor"`
+	Team  struct {
+		CombinedSlug string
+	} `graphql:"... on Team"`
+}
+
+type GithubReferencedSubject struct {
+	Issue struct {
+		URL string
+	} `graphql:"... on Issue"`
+	PullRequest struct {
+		URL string
+	} `graphql:"... on PullRequest"`
+}
+
+type GithubTimelineItem struct {
+	Typename      string `graphql:"__typename"`
+	AssignedEvent struct {
+		GithubTimelineEvent
+		Assignee GithubAssignee
+	} `graphql:"... on AssignedEvent"`
+	UnassignedEvent struct {
+		GithubTimelineEvent
+		Assignee GithubAssignee
+	} `graphql:"... on UnassignedEvent"`
+	LabeledEvent struct {
+		GithubTimelineEvent
+		Label GithubLabel
+	} `graphql:"... on LabeledEvent"`
+	UnlabeledEvent struct {
+		GithubTimelineEvent
+		Label GithubLabel
+	} `graphql:"... on UnlabeledEvent"`
+	MilestonedEvent struct {
+		GithubTimelineEvent
+		MilestoneTitle string
+	} `graphql:"... on MilestonedEvent"`
+	DemilestonedEvent struct {
+		GithubTimelineEvent
+		MilestoneTitle string
+	} `graphql:"... on DemilestonedEvent"`
+	ReferencedEvent struct {
+		GithubTimelineEvent
+		Commit struct {
+			Oid string
+		}
+	} `graphql:"... on ReferencedEvent"`
+	CrossReferencedEvent struct {
+		GithubTimelineEvent
+		Source GithubReferencedSubject
+	} `graphql:"... on CrossReferencedEvent"`
+	RenamedTitleEvent struct {
+		GithubTimelineEvent
+		PreviousTitle string
+		CurrentTitle  string
+	} `graphql:"... on RenamedTitleEvent"`
+	ReopenedEvent struct {
+		GithubTimelineEvent
+	} `graphql:"... on ReopenedEvent"`
+	ClosedEvent struct {
+		GithubTimelineEvent
+	} `graphql:"... on ClosedEvent"`
+}
+
+type GithubPullrequestTimelineItem struct {
+	GithubTimelineItem
+	ReviewRequestedEvent struct {
+		GithubTimelineEvent
+		RequestedReviewer GithubReviewer
+	} `graphql:"... on ReviewRequestedEvent"`
+	ReviewRequestRemovedEvent struct {
+		GithubTimelineEvent
+		RequestedReviewer GithubReviewer
+	} `graphql:"... on ReviewRequestRemovedEvent"`
+	ReadyForReviewEvent struct {
+		GithubTimelineEvent
+	} `graphql:"... on ReadyForReviewEvent"`
+	ConvertToDraftEvent struct {
+		GithubTimelineEvent
+	} `graphql:"... on ConvertToDraftEvent"`
+	MergedEvent struct {
+		GithubTimelineEvent
+	} `graphql:"... on MergedEvent"`
 }
 
 type PageInfo struct {
@@ -169,5 +480,108 @@ type PageInfo struct {
 type RateLimit struct {
 	Cost      int
 	Remaining int
-	ResetAt   string
+	ResetAt   time.Time
+}
+
+type GithubGitActor struct {
+	Name  string
+	Email string
+	Date  time.Time
+	User  GithubActor
+}
+
+type GithubCommit struct {
+	Oid                     string
+	URL                     string
+	MessageHeadline         string
+	Message                 string
+	Author                  GithubGitActor
+	Committer               GithubGitActor
+	AuthoredDate            time.Time
+	CommittedDate           time.Time
+	Additions               int
+	Deletions               int
+	ChangedFilesIfAvailable *int
+	Parents                 struct {
+		TotalCount int
+	}
+	Signature struct {
+		IsValid           bool
+		State             string
+		WasSignedByGitHub bool
+		Signer            GithubActor
+	}
+}
+
+type GithubProfile struct {
+	Typename string `graphql:"__typename"`
+	User     struct {
+		ID         string
+		Name       string
+		Email      string
+		Company    string
+		Location   string
+		Bio        string
+		WebsiteURL string
+		Followers  struct {
+			TotalCount int
+		}
+		Following struct {
+			TotalCount int
+		}
+		Repositories struct {
+			TotalCount int
+		} `graphql:"repositories(privacy: PUBLIC, ownerAffiliations: OWNER)"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	} `graphql:"... on User"`
+	Organization struct {
+		ID           string
+		Name         string
+		Email        string
+		Location     string
+		Description  string
+		WebsiteURL   string
+		Repositories struct {
+			TotalCount int
+		} `graphql:"repositories(privacy: PUBLIC)"`
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	} `graphql:"... on Organization"`
+}
+
+type GithubRepositoryLabel struct {
+	ID          string
+	Name        string
+	Color       string
+	Description string
+	IsDefault   bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type GithubMilestone struct {
+	ID                 string
+	Number             int
+	Title              string
+	Description        string
+	URL                string
+	State              string
+	DueOn              time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	ClosedAt           time.Time
+	ProgressPercentage float64
+	OpenIssues         struct {
+		TotalCount int
+	} `graphql:"openIssues: issues(states: OPEN)"`
+	ClosedIssues struct {
+		TotalCount int
+	} `graphql:"closedIssues: issues(states: CLOSED)"`
+	OpenPullrequests struct {
+		TotalCount int
+	} `graphql:"openPullrequests: pullRequests(states: OPEN)"`
+	ClosedPullrequests struct {
+		TotalCount int
+	} `graphql:"closedPullrequests: pullRequests(states: [CLOSED, MERGED])"`
 }
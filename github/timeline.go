@@ -0,0 +1,218 @@
+package github
+
+import (
+	"github.com/eveldcorp/devrel-github/database"
+	"github.com/shurcooL/githubv4"
+)
+
+// The timeline events queried for issues.
+var issueItemTypes = []githubv4.IssueTimelineItemsItemType{
+	githubv4.IssueTimelineItemsItemTypeAssignedEvent,
+	githubv4.IssueTimelineItemsItemTypeUnassignedEvent,
+	githubv4.IssueTimelineItemsItemTypeLabeledEvent,
+	githubv4.IssueTimelineItemsItemTypeUnlabeledEvent,
+	githubv4.IssueTimelineItemsItemTypeMilestonedEvent,
+	githubv4.IssueTimelineItemsItemTypeDemilestonedEvent,
+	githubv4.IssueTimelineItemsItemTypeReferencedEvent,
+	githubv4.IssueTimelineItemsItemTypeCrossReferencedEvent,
+	githubv4.IssueTimelineItemsItemTypeRenamedTitleEvent,
+	githubv4.IssueTimelineItemsItemTypeReopenedEvent,
+	githubv4.IssueTimelineItemsItemTypeClosedEvent,
+}
+
+// The timeline events queried for pullrequests.
+var pullrequestItemTypes = []githubv4.PullRequestTimelineItemsItemType{
+	githubv4.PullRequestTimelineItemsItemTypeAssignedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeUnassignedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeLabeledEvent,
+	githubv4.PullRequestTimelineItemsItemTypeUnlabeledEvent,
+	githubv4.PullRequestTimelineItemsItemTypeMilestonedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeDemilestonedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeReferencedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeCrossReferencedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeRenamedTitleEvent,
+	githubv4.PullRequestTimelineItemsItemTypeReopenedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeClosedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeReviewRequestedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeReviewRequestRemovedEvent,
+	githubv4.PullRequestTimelineItemsItemTypeReadyForReviewEvent,
+	githubv4.PullRequestTimelineItemsItemTypeConvertToDraftEvent,
+	githubv4.PullRequestTimelineItemsItemTypeMergedEvent,
+}
+
+// Query the timeline events of an issue after cursor.
+func (g *GithubImpl) queryIssueTimeline(node string, cursor string) ([]GithubTimelineItem, error) {
+	var query struct {
+		Node struct {
+			Issue struct {
+				TimelineItems struct {
+					Nodes    []GithubTimelineItem
+					PageInfo PageInfo
+				} `graphql:"timelineItems(first: 100, after: $cursor, itemTypes: $issueItemTypes)"`
+			} `graphql:"... on Issue"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	variables := map[string]interface{}{
+		"issueItemTypes": issueItemTypes,
+	}
+
+	items := []GithubTimelineItem{}
+	err := g.queryNestedWith("issue timeline", node, cursor, variables, &query, func() PageInfo {
+		items = append(items, query.Node.Issue.TimelineItems.Nodes...)
+		return query.Node.Issue.TimelineItems.PageInfo
+	})
+
+	return items, err
+}
+
+// Query the timeline events of a pullrequest after cursor.
+func (g *GithubImpl) queryPullrequestTimeline(node string, cursor string) ([]GithubPullrequestTimelineItem, error) {
+	var query struct {
+		Node struct {
+			PullRequest struct {
+				TimelineItems struct {
+					Nodes    []GithubPullrequestTimelineItem
+					PageInfo PageInfo
+				} `graphql:"timelineItems(first: 100, after: $cursor, itemTypes: $pullrequestItemTypes)"`
+			} `graphql:"... on PullRequest"`
+		} `graphql:"node(id: $node)"`
+		RateLimit RateLimit
+	}
+
+	variables := map[string]interface{}{
+		"pullrequestItemTypes": pullrequestItemTypes,
+	}
+
+	items := []GithubPullrequestTimelineItem{}
+	err := g.queryNestedWith("pullrequest timeline", node, cursor, variables, &query, func() PageInfo {
+		items = append(items, query.Node.PullRequest.TimelineItems.Nodes...)
+		return query.Node.PullRequest.TimelineItems.PageInfo
+	})
+
+	return items, err
+}
+
+// Map queried timeline items of an issue.
+func mapTimeline(nodes []GithubTimelineItem) []database.TimelineEvent {
+	events := []database.TimelineEvent{}
+
+	for _, t := range nodes {
+		event, ok := mapTimelineItem(t)
+		if ok {
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+// Map queried timeline items of a pullrequest.
+func mapPullrequestTimeline(nodes []GithubPullrequestTimelineItem) []database.TimelineEvent {
+	events := []database.TimelineEvent{}
+
+	for _, t := range nodes {
+		event, ok := mapPullrequestTimelineItem(t)
+		if ok {
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+// Map a timeline item that issues and pullrequests have in common, ok is false
+// for an unknown type of item.
+func mapTimelineItem(t GithubTimelineItem) (database.TimelineEvent, bool) {
+	switch t.Typename {
+	case "AssignedEvent":
+		event := mapTimelineEvent("assigned", t.AssignedEvent.GithubTimelineEvent)
+		event.Assignee = t.AssignedEvent.Assignee.Actor.Login
+		return event, true
+	case "UnassignedEvent":
+		event := mapTimelineEvent("unassigned", t.UnassignedEvent.GithubTimelineEvent)
+		event.Assignee = t.UnassignedEvent.Assignee.Actor.Login
+		return event, true
+	case "LabeledEvent":
+		event := mapTimelineEvent("labeled", t.LabeledEvent.GithubTimelineEvent)
+		event.Label = t.LabeledEvent.Label.Name
+		return event, true
+	case "UnlabeledEvent":
+		event := mapTimelineEvent("unlabeled", t.UnlabeledEvent.GithubTimelineEvent)
+		event.Label = t.UnlabeledEvent.Label.Name
+		return event, true
+	case "MilestonedEvent":
+		event := mapTimelineEvent("milestoned", t.MilestonedEvent.GithubTimelineEvent)
+		event.Milestone = t.MilestonedEvent.MilestoneTitle
+		return event, true
+	case "DemilestonedEvent":
+		event := mapTimelineEvent("demilestoned", t.DemilestonedEvent.GithubTimelineEvent)
+		event.Milestone = t.DemilestonedEvent.MilestoneTitle
+		return event, true
+	case "ReferencedEvent":
+		event := mapTimelineEvent("referenced", t.ReferencedEvent.GithubTimelineEvent)
+		event.Source = t.ReferencedEvent.Commit.Oid
+		return event, true
+	case "CrossReferencedEvent":
+		event := mapTimelineEvent("cross_referenced", t.CrossReferencedEvent.GithubTimelineEvent)
+		event.Source = t.CrossReferencedEvent.Source.Issue.URL
+		if event.Source == "" {
+			event.Source = t.CrossReferencedEvent.Source.PullRequest.URL
+		}
+		return event, true
+	case "RenamedTitleEvent":
+		event := mapTimelineEvent("renamed", t.RenamedTitleEvent.GithubTimelineEvent)
+		event.PreviousTitle = t.RenamedTitleEvent.PreviousTitle
+		event.Title = t.RenamedTitleEvent.CurrentTitle
+		return event, true
+	case "ReopenedEvent":
+		return mapTimelineEvent("reopened", t.ReopenedEvent.GithubTimelineEvent), true
+	case "ClosedEvent":
+		return mapTimelineEvent("closed", t.ClosedEvent.GithubTimelineEvent), true
+	}
+
+	return database.TimelineEvent{}, false
+}
+
+// Map a timeline item of a pullrequest, ok is false for an unknown type of
+// item.
+func mapPullrequestTimelineItem(t GithubPullrequestTimelineItem) (database.TimelineEvent, bool) {
+	switch t.Typename {
+	case "ReviewRequestedEvent":
+		event := mapTimelineEvent("review_requested", t.ReviewRequestedEvent.GithubTimelineEvent)
+		event.Reviewer = mapReviewer(t.ReviewRequestedEvent.RequestedReviewer)
+		return event, true
+	case "ReviewRequestRemovedEvent":
+		event := mapTimelineEvent("review_request_removed", t.ReviewRequestRemovedEvent.GithubTimelineEvent)
+		event.Reviewer = mapReviewer(t.ReviewRequestRemovedEvent.RequestedReviewer)
+		return event, true
+	case "ReadyForReviewEvent":
+		return mapTimelineEvent("ready_for_review", t.ReadyForReviewEvent.GithubTimelineEvent), true
+	case "ConvertToDraftEvent":
+		return mapTimelineEvent("converted_to_draft", t.ConvertToDraftEvent.GithubTimelineEvent), true
+	case "MergedEvent":
+		return mapTimelineEvent("merged", t.MergedEvent.GithubTimelineEvent), true
+	}
+
+	return mapTimelineItem(t.GithubTimelineItem)
+}
+
+// Map the fields every timeline event has.
+func mapTimelineEvent(kind string, e GithubTimelineEvent) database.TimelineEvent {
+	return database.TimelineEvent{
+		ID:        e.ID,
+		Event:     kind,
+		Actor:     e.Actor.Login,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// The login of a requested user, or the organization/slug of a requested team.
+func mapReviewer(r GithubReviewer) string {
+	if r.Team.CombinedSlug != "" {
+		return r.Team.CombinedSlug
+	}
+
+	return r.Actor.Login
+}
@@ -0,0 +1,237 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/database"
+	githubv3 "github.com/google/go-github/v50/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// The number of profiles passed to fn at once.
+const usersPageSize = 100
+
+// The number of profiles queried by a single GraphQL query.
+const usersQuerySize = 50
+
+// QueryUsers queries the profiles of users, bots and organizations by their
+// login, calling fn with every page of profiles as soon as it has been
+// queried. A login that no longer exists is returned without a type, so that it
+// is not queried again before the next refresh.
+func (g *GithubImpl) QueryUsers(logins []database.Login, fn func(users []database.User) error) error {
+	users := []database.User{}
+	add := func(user database.User) error {
+		users = append(users, user)
+		if len(users) < usersPageSize {
+			return nil
+		}
+
+		err := fn(users)
+		users = []database.User{}
+		return err
+	}
+
+	// Users and organizations are queried in batches. Bots can only be looked
+	// up by their login with the REST API.
+	owners := []database.Login{}
+	for _, login := range logins {
+		if !login.Bot && !strings.HasSuffix(login.Login, "[bot]") {
+			owners = append(owners, login)
+			continue
+		}
+
+		g.logger.Debug("Querying bot", "login", login.Login)
+		user, err := g.queryBot(login.Login)
+		if err != nil {
+			return err
+		}
+
+		err = add(user)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < len(owners); i += usersQuerySize {
+		j := i + usersQuerySize
+		if j > len(owners) {
+			j = len(owners)
+		}
+
+		g.logger.Debug("Querying users", "from", i+1, "to", j, "users", len(owners))
+		profiles, err := g.queryProfiles(owners[i:j])
+		if err != nil {
+			return err
+		}
+
+		for _, user := range profiles {
+			err := add(user)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(users) > 0 {
+		return fn(users)
+	}
+
+	return nil
+}
+
+// Query the profiles of users and organizations with a single query, in which
+// every login is looked up by an alias of its own.
+func (g *GithubImpl) queryProfiles(logins []database.Login) ([]database.User, error) {
+	fields := []reflect.StructField{}
+	variables := map[string]interface{}{}
+	for i, login := range logins {
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("Owner%d", i),
+			Type: reflect.TypeOf(GithubProfile{}),
+			Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"owner%d: repositoryOwner(login: $login%d)"`, i, i)),
+		})
+		variables[fmt.Sprintf("login%d", i)] = githubv4.String(login.Login)
+	}
+	fields = append(fields, reflect.StructField{
+		Name: "RateLimit",
+		Type: reflect.TypeOf(RateLimit{}),
+	})
+
+	query := reflect.New(reflect.StructOf(fields))
+	err := g.v4.Query(g.ctx, query.Interface(), variables)
+	if err != nil {
+		return nil, err
+	}
+
+	collectedAt := time.Now().UTC().Round(0)
+	users := []database.User{}
+	for i, login := range logins {
+		profile := query.Elem().Field(i).Interface().(GithubProfile)
+		if profile.Typename == "" {
+			g.logger.Warn("User not found", "login", login.Login)
+		}
+
+		users = append(users, mapProfile(g.host, login.Login, profile, collectedAt))
+	}
+
+	return users, nil
+}
+
+// Map a queried profile of a user or organization, which is empty when the
+// login does not exist.
+func mapProfile(host string, login string, p GithubProfile, collectedAt time.Time) database.User {
+	user := database.User{
+		Host:        host,
+		Login:       login,
+		Type:        p.Typename,
+		CollectedAt: collectedAt,
+	}
+
+	switch p.Typename {
+	case "User":
+		u := p.User
+		user.ID = u.ID
+		user.Name = u.Name
+		user.Email = u.Email
+		user.Company = u.Company
+		user.Location = u.Location
+		user.Bio = u.Bio
+		user.Blog = u.WebsiteURL
+		user.Followers = u.Followers.TotalCount
+		user.Following = u.Following.TotalCount
+		user.PublicRepos = u.Repositories.TotalCount
+		user.CreatedAt = u.CreatedAt
+		user.UpdatedAt = u.UpdatedAt
+	case "Organization":
+		o := p.Organization
+		user.ID = o.ID
+		user.Name = o.Name
+		user.Email = o.Email
+		user.Location = o.Location
+		user.Bio = o.Description
+		user.Blog = o.WebsiteURL
+		user.PublicRepos = o.Repositories.TotalCount
+		user.CreatedAt = o.CreatedAt
+		user.UpdatedAt = o.UpdatedAt
+	}
+
+	return user
+}
+
+// Query the profile of a bot. The GraphQL API names bots without their [bot]
+// suffix, which the REST API needs.
+func (g *GithubImpl) queryBot(login string) (database.User, error) {
+	user := database.User{
+		Host:        g.host,
+		Login:       login,
+		CollectedAt: time.Now().UTC().Round(0),
+	}
+
+	name := login
+	if !strings.HasSuffix(name, "[bot]") {
+		name += "[bot]"
+	}
+
+	u, _, err := g.v3.Users.Get(g.ctx, name)
+	if err != nil {
+		var e *githubv3.ErrorResponse
+		if errors.As(err, &e) && e.Response.StatusCode == http.StatusNotFound {
+			g.logger.Warn("User not found", "login", login)
+			return user, nil
+		}
+
+		return user, err
+	}
+
+	user.ID = u.GetNodeID()
+	user.Type = u.GetType()
+	user.Name = u.GetName()
+	user.Email = u.GetEmail()
+	user.Company = u.GetCompany()
+	user.Location = u.GetLocation()
+	user.Bio = u.GetBio()
+	user.Blog = u.GetBlog()
+	user.Followers = u.GetFollowers()
+	user.Following = u.GetFollowing()
+	user.PublicRepos = u.GetPublicRepos()
+	user.CreatedAt = u.GetCreatedAt().Time
+	user.UpdatedAt = u.GetUpdatedAt().Time
+
+	return user, nil
+}
+
+// QueryOrganizationMembers queries the logins of the members of an
+// organization. Only public members are returned, unless the token belongs to
+// a member of the organization.
+func (g *GithubImpl) QueryOrganizationMembers(organization string) ([]string, error) {
+	ctx := g.ctx
+	members := []string{}
+
+	page := 1
+	for {
+		g.logger.Debug("Querying organization members", "organization", organization, "page", page)
+		users, response, err := g.v3.Organizations.ListMembers(ctx, organization, &githubv3.ListMembersOptions{
+			ListOptions: githubv3.ListOptions{Page: page, PerPage: 100},
+		})
+		if err != nil {
+			return members, err
+		}
+
+		for _, u := range users {
+			members = append(members, u.GetLogin())
+		}
+
+		if response.NextPage == 0 {
+			break
+		}
+
+		page++
+	}
+
+	return members, nil
+}
@@ -0,0 +1,132 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eveldcorp/devrel-github/database"
+	githubv3 "github.com/google/go-github/v50/github"
+	"github.com/hashicorp/go-hclog"
+	"github.com/shurcooL/githubv4"
+)
+
+func TestQueryUsers(t *testing.T) {
+	// The profiles GitHub knows by their login.
+	owners := map[string]string{
+		"octocat":   `{"__typename": "User", "id": "U_1", "name": "The Octocat", "company": "@github", "websiteUrl": "https://github.blog", "followers": {"totalCount": 10}, "following": {"totalCount": 2}, "repositories": {"totalCount": 8}}`,
+		"hashicorp": `{"__typename": "Organization", "id": "O_1", "name": "HashiCorp", "description": "Automation", "repositories": {"totalCount": 500}}`,
+	}
+	bots := map[string]string{
+		"dependabot[bot]": `{"login": "dependabot[bot]", "node_id": "B_1", "type": "Bot"}`,
+	}
+
+	queries := 0
+	lookups := []string{}
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		headers := map[string]string{"Content-Type": "application/json"}
+
+		if req.URL.Path != "/graphql" {
+			login := strings.TrimPrefix(req.URL.Path, "/users/")
+			lookups = append(lookups, login)
+
+			resp := response(req.URL.String(), http.StatusNotFound, headers, `{"message": "Not Found"}`)
+			if bot, ok := bots[login]; ok {
+				resp = response(req.URL.String(), http.StatusOK, headers, bot)
+			}
+			resp.Request = req
+			return resp, nil
+		}
+
+		queries++
+		var body struct {
+			Variables map[string]string
+		}
+		err := json.NewDecoder(req.Body).Decode(&body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data := []string{`"rateLimit": {"cost": 1, "remaining": 4999, "resetAt": "2023-01-01T00:00:00Z"}`}
+		for i := 0; i < len(body.Variables); i++ {
+			profile, ok := owners[body.Variables[fmt.Sprintf("login%d", i)]]
+			if !ok {
+				profile = "null"
+			}
+			data = append(data, fmt.Sprintf(`"owner%d": %s`, i, profile))
+		}
+
+		resp := response(req.URL.String(), http.StatusOK, headers, fmt.Sprintf(`{"data": {%s}}`, strings.Join(data, ", ")))
+		resp.Request = req
+		return resp, nil
+	})
+
+	client := &http.Client{Transport: next}
+	g := &GithubImpl{
+		ctx:    context.Background(),
+		v4:     githubv4.NewClient(client),
+		v3:     githubv3.NewClient(client),
+		host:   "github.com",
+		logger: hclog.NewNullLogger(),
+	}
+
+	logins := []database.Login{
+		{Login: "octocat"},
+		{Login: "dependabot", Bot: true},
+		{Login: "hashicorp"},
+		{Login: "ghost"},
+		{Login: "renovate", Bot: true},
+	}
+	for i := 0; i < usersQuerySize; i++ {
+		logins = append(logins, database.Login{Login: fmt.Sprintf("user%d", i)})
+	}
+
+	users := []database.User{}
+	err := g.QueryUsers(logins, func(page []database.User) error {
+		users = append(users, page...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if queries != 2 {
+		t.Errorf("got %d GraphQL queries, want 2", queries)
+	}
+
+	// Bots are looked up by their [bot] login only.
+	if want := []string{"dependabot[bot]", "renovate[bot]"}; !reflect.DeepEqual(lookups, want) {
+		t.Errorf("got REST lookups %v, want %v", lookups, want)
+	}
+
+	if len(users) != len(logins) {
+		t.Fatalf("got %d users, want %d", len(users), len(logins))
+	}
+
+	byLogin := map[string]database.User{}
+	for _, u := range users {
+		if u.CollectedAt.IsZero() || u.Host != "github.com" {
+			t.Errorf("user %s without host or collection time", u.Login)
+		}
+		u.CollectedAt = time.Time{}
+		byLogin[u.Login] = u
+	}
+
+	want := map[string]database.User{
+		"octocat":    {Host: "github.com", Login: "octocat", ID: "U_1", Type: "User", Name: "The Octocat", Company: "@github", Blog: "https://github.blog", Followers: 10, Following: 2, PublicRepos: 8},
+		"hashicorp":  {Host: "github.com", Login: "hashicorp", ID: "O_1", Type: "Organization", Name: "HashiCorp", Bio: "Automation", PublicRepos: 500},
+		"dependabot": {Host: "github.com", Login: "dependabot", ID: "B_1", Type: "Bot"},
+		"ghost":      {Host: "github.com", Login: "ghost"},
+		"renovate":   {Host: "github.com", Login: "renovate"},
+	}
+	for login, w := range want {
+		if got := byLogin[login]; !reflect.DeepEqual(got, w) {
+			t.Errorf("got %+v, want %+v", got, w)
+		}
+	}
+}